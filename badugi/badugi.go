@@ -0,0 +1,146 @@
+package badugi
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/aultimus/gosouth/card"
+	"github.com/aultimus/gosouth/draw"
+	"github.com/aultimus/gosouth/hand"
+)
+
+const sizeHand = 4
+
+// Game is Badugi played with three drawing rounds
+var Game = draw.Game{
+	Name:     "Badugi",
+	HandSize: sizeHand,
+	Draws:    3,
+	Eval:     Showdown,
+}
+
+// Value is the playing part of a Badugi hand, the largest subset of
+// cards of distinct suits and ranks, sorted from highest to lowest.
+// Aces are low.
+type Value struct {
+	Hand hand.Hand
+}
+
+// Evaluate returns the best Value that can be formed from a four card hand
+func Evaluate(h hand.Hand) (*Value, error) {
+	if len(h) != sizeHand {
+		return nil, fmt.Errorf("Badugi hand should be %d cards, not %d cards",
+			sizeHand, len(h))
+	}
+	var best *Value
+	// try every subset of the hand
+	for mask := 1; mask < 1<<sizeHand; mask++ {
+		var sub hand.Hand
+		for i := 0; i < sizeHand; i++ {
+			if mask&(1<<i) != 0 {
+				sub = append(sub, h[i])
+			}
+		}
+		if !distinct(sub) {
+			continue
+		}
+		v := &Value{Hand: sortHigh(sub)}
+		if best == nil || Compare(v, best) == hand.H1Win {
+			best = v
+		}
+	}
+	return best, nil
+}
+
+// Compare compares two Badugi values, determining whether v1
+// wins, v2 wins or if they draw. More cards beat fewer, then the
+// lowest high card wins.
+func Compare(v1, v2 *Value) hand.OUTCOME {
+	if len(v1.Hand) > len(v2.Hand) {
+		return hand.H1Win
+	} else if len(v2.Hand) > len(v1.Hand) {
+		return hand.H2Win
+	}
+	for i := range v1.Hand {
+		r1 := rankIndex(v1.Hand[i].Rank)
+		r2 := rankIndex(v2.Hand[i].Rank)
+		if r1 < r2 {
+			return hand.H1Win
+		} else if r2 < r1 {
+			return hand.H2Win
+		}
+	}
+	return hand.Draw
+}
+
+// Showdown determines the winner of two to many Badugi hands,
+// returning the winning index/ drawing indexes
+func Showdown(hands []hand.Hand) ([]int, error) {
+	var best *Value
+	var winners []int
+	for i, h := range hands {
+		v, err := Evaluate(h)
+		if err != nil {
+			return nil, err
+		}
+		if best == nil {
+			best, winners = v, []int{i}
+			continue
+		}
+		switch Compare(v, best) {
+		case hand.H1Win:
+			best, winners = v, []int{i}
+		case hand.Draw:
+			winners = append(winners, i)
+		}
+	}
+	return winners, nil
+}
+
+// String describes the value e.g. "7-4-3-A badugi" or "three card 8-5-2"
+func (v *Value) String() string {
+	var ranks []string
+	for _, c := range v.Hand {
+		ranks = append(ranks, string(c.Rank))
+	}
+	s := strings.Join(ranks, "-")
+	switch len(v.Hand) {
+	case 4:
+		return s + " badugi"
+	case 3:
+		return "three card " + s
+	case 2:
+		return "two card " + s
+	}
+	return "one card " + s
+}
+
+// distinct returns true if no two cards in h share a suit or rank
+func distinct(h hand.Hand) bool {
+	ranks := make(map[card.RANK]bool)
+	suits := make(map[card.SUIT]bool)
+	for _, c := range h {
+		if ranks[c.Rank] || suits[c.Suit] {
+			return false
+		}
+		ranks[c.Rank] = true
+		suits[c.Suit] = true
+	}
+	return true
+}
+
+func sortHigh(h hand.Hand) hand.Hand {
+	sort.Slice(h, func(i, j int) bool {
+		return rankIndex(h[i].Rank) > rankIndex(h[j].Rank)
+	})
+	return h
+}
+
+// rankIndex orders ranks with the ace low
+func rankIndex(r card.RANK) int {
+	if r == card.Ace {
+		return -1
+	}
+	return card.RankIndexes[r]
+}
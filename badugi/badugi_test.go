@@ -0,0 +1,86 @@
+package badugi
+
+import (
+	"testing"
+
+	"github.com/aultimus/gosouth/card"
+	"github.com/aultimus/gosouth/deck"
+	"github.com/aultimus/gosouth/draw"
+	"github.com/aultimus/gosouth/hand"
+	"github.com/stretchr/testify/assert"
+)
+
+func mkHand(cards ...string) hand.Hand {
+	var h hand.Hand
+	for _, s := range cards {
+		h = append(h, card.New(card.RANK(s[:1]), card.SUIT(s[1:])))
+	}
+	return h
+}
+
+func TestEvaluate(t *testing.T) {
+	a := assert.New(t)
+
+	v, err := Evaluate(mkHand("AC", "2D", "3H", "4S"))
+	a.NoError(err)
+	a.Equal(4, len(v.Hand))
+	a.Equal("4-3-2-A badugi", v.String())
+
+	// paired, one of the fours plays
+	v, err = Evaluate(mkHand("KC", "4D", "4H", "2S"))
+	a.NoError(err)
+	a.Equal("three card K-4-2", v.String())
+	v, err = Evaluate(mkHand("KC", "4C", "3H", "2S"))
+	a.NoError(err)
+	a.Equal("three card 4-3-2", v.String())
+
+	// four of a suit plays as a single card
+	v, err = Evaluate(mkHand("KC", "8C", "5C", "3C"))
+	a.NoError(err)
+	a.Equal("one card 3", v.String())
+
+	_, err = Evaluate(mkHand("KC", "8C", "5C"))
+	a.Error(err)
+}
+
+func TestCompare(t *testing.T) {
+	a := assert.New(t)
+	eval := func(cards ...string) *Value {
+		v, err := Evaluate(mkHand(cards...))
+		a.NoError(err)
+		return v
+	}
+	// any badugi beats any three card hand
+	a.Equal(hand.H1Win, Compare(eval("KC", "QD", "JH", "TS"), eval("AC", "2C", "3H", "4S")))
+	// lowest high card
+	a.Equal(hand.H2Win, Compare(eval("8C", "4D", "3H", "2S"), eval("7C", "6D", "5H", "4S")))
+	// then the next highest
+	a.Equal(hand.H1Win, Compare(eval("8C", "4D", "3H", "2S"), eval("8D", "5C", "3S", "2H")))
+	a.Equal(hand.Draw, Compare(eval("8C", "4D", "3H", "2S"), eval("8D", "4C", "3S", "2H")))
+}
+
+func TestShowdown(t *testing.T) {
+	a := assert.New(t)
+	w, err := Showdown([]hand.Hand{
+		mkHand("KC", "8C", "5C", "3C"),
+		mkHand("8C", "4D", "3H", "2S"),
+		mkHand("8D", "4C", "3S", "2H"),
+	})
+	a.NoError(err)
+	a.Equal([]int{1, 2}, w)
+}
+
+func TestDrawGame(t *testing.T) {
+	a := assert.New(t)
+	tbl, err := draw.Deal(Game, deck.NewShuffled(), 4)
+	a.NoError(err)
+	for tbl.DrawsRemaining() > 0 {
+		a.NoError(tbl.Draw([][]int{{0, 1}, nil, {3}, {0, 1, 2, 3}}))
+	}
+	for _, h := range tbl.Hands {
+		a.Equal(sizeHand, len(h))
+	}
+	w, err := tbl.Showdown()
+	a.NoError(err)
+	a.NotEmpty(w)
+}
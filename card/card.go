@@ -1,6 +1,10 @@
 package card
 
-import "fmt"
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
 
 // Go does not have variant types :(
 const (
@@ -9,6 +13,13 @@ const (
 	NumCards = 4 * 13
 )
 
+var (
+	// ErrInvalidCard is wrapped by errors for a card of unknown rank or suit
+	ErrInvalidCard = errors.New("invalid card")
+	// ErrDuplicateCard is wrapped by errors for a card given more than once
+	ErrDuplicateCard = errors.New("duplicate card")
+)
+
 // RANK represents a card rank "2" -> "A"
 type RANK string
 
@@ -41,6 +52,8 @@ const (
 	King = RANK("K")
 	// Ace Rank constant
 	Ace = RANK("A")
+	// Joker Rank constant, jokers have no suit
+	Joker = RANK("X")
 )
 
 // Ranks is a slice of all ranks
@@ -121,11 +134,39 @@ func New(v RANK, s SUIT) *Card {
 	}
 }
 
+// NewJoker creates a new joker card
+func NewJoker() *Card {
+	return &Card{Rank: Joker}
+}
+
+// IsJoker returns true if the card is a joker
+func (c *Card) IsJoker() bool {
+	return c.Rank == Joker
+}
+
 // String representation of a card
 func (c *Card) String() string {
 	return fmt.Sprintf("%s%s", c.Rank, c.Suit)
 }
 
+// Validate returns an error wrapping ErrInvalidCard
+// unless c is a joker or of known rank and suit
+func Validate(c *Card) error {
+	if c == nil {
+		return fmt.Errorf("%w, no card", ErrInvalidCard)
+	}
+	if c.IsJoker() {
+		return nil
+	}
+	if _, ok := RankIndexes[c.Rank]; !ok {
+		return fmt.Errorf("%w %s, unknown rank %q", ErrInvalidCard, c, c.Rank)
+	}
+	if _, ok := SuitIndexes[c.Suit]; !ok {
+		return fmt.Errorf("%w %s, unknown suit %q", ErrInvalidCard, c, c.Suit)
+	}
+	return nil
+}
+
 // Connected returns true if the given
 // card is adjacent (connected in a straight)
 // to the current card, includes K <-> A, A <-> 2
@@ -152,8 +193,35 @@ func makeCardMatrix() [NumSuit][NumRanks]*Card {
 	return matrix
 }
 
-// FromString converts a string into a card struct representation
+// Parse reads a card written as its rank then suit, such as "AS", "Td"
+// or "10h", in either case. "X" is a joker.
+func Parse(s string) (*Card, error) {
+	u := strings.ToUpper(s)
+	if u == string(Joker) {
+		return NewJoker(), nil
+	}
+	if strings.HasPrefix(u, "10") {
+		u = string(Ten) + u[2:]
+	}
+	if len(u) != 2 {
+		return nil, fmt.Errorf("%w %q", ErrInvalidCard, s)
+	}
+	r, su := RANK(u[:1]), SUIT(u[1:])
+	if _, ok := RankIndexes[r]; !ok {
+		return nil, fmt.Errorf("%w %q, unknown rank %s", ErrInvalidCard, s, r)
+	}
+	if _, ok := SuitIndexes[su]; !ok {
+		return nil, fmt.Errorf("%w %q, unknown suit %s", ErrInvalidCard, s, su)
+	}
+	return New(r, su), nil
+}
+
+// FromString converts a string into a card struct representation,
+// it returns nil if the string is not a card
 func FromString(s string) *Card {
-	// TODO
-	return New(Ace, Spades)
+	c, err := Parse(s)
+	if err != nil {
+		return nil
+	}
+	return c
 }
@@ -43,3 +43,66 @@ func TestConnected(t *testing.T) {
 		}
 	}
 }
+
+func TestParse(t *testing.T) {
+	a := assert.New(t)
+	for s, want := range map[string]*Card{
+		"AS":  New(Ace, Spades),
+		"td":  New(Ten, Diamonds),
+		"10h": New(Ten, Hearts),
+		"2c":  New(Two, Clubs),
+		"x":   NewJoker(),
+	} {
+		c, err := Parse(s)
+		a.NoError(err, s)
+		a.Equal(want, c, s)
+	}
+	for _, s := range []string{"", "A", "1S", "AZ", "ASS", "11h"} {
+		_, err := Parse(s)
+		a.ErrorIs(err, ErrInvalidCard, s)
+	}
+	a.Equal(New(King, Clubs), FromString("KC"))
+	a.Nil(FromString("KZ"))
+}
+
+func TestSet(t *testing.T) {
+	a := assert.New(t)
+	as, ks, ah := New(Ace, Spades), New(King, Spades), New(Ace, Hearts)
+	s, err := NewSet(as, ks)
+	a.NoError(err)
+	a.Equal(2, s.Count())
+	a.True(s.Contains(New(Ace, Spades)))
+	a.False(s.Contains(ah))
+	a.False(s.Contains(NewJoker()))
+
+	o, err := NewSet(ah, as)
+	a.NoError(err)
+	a.Equal(3, s.Union(o).Count())
+	a.Equal("{AS}", s.Intersect(o).String())
+	a.Equal("{KS}", s.Difference(o).String())
+	a.True(s.Union(o).ContainsAll(s))
+	a.False(s.ContainsAll(o))
+
+	// cards come out in the order of a fresh deck
+	a.Equal("{AH KS AS}", s.Union(o).String())
+	a.Equal([]*Card{ah, ks, as}, s.Union(o).Cards())
+	a.Equal(NumCards, FullSet.Count())
+	a.Equal(NumCards, len(FullSet.Cards()))
+
+	_, err = NewSet(as, New(Ace, Spades))
+	a.ErrorIs(err, ErrDuplicateCard)
+	_, err = NewSet(NewJoker())
+	a.ErrorIs(err, ErrInvalidCard)
+	_, err = Index(&Card{Rank: Ace, Suit: SUIT("Z")})
+	a.ErrorIs(err, ErrInvalidCard)
+}
+
+func TestValidate(t *testing.T) {
+	a := assert.New(t)
+	a.NoError(Validate(New(Ace, Spades)))
+	a.NoError(Validate(NewJoker()))
+	a.ErrorIs(Validate(nil), ErrInvalidCard)
+	a.ErrorIs(Validate(&Card{Rank: RANK("1"), Suit: Spades}), ErrInvalidCard)
+	a.ErrorIs(Validate(&Card{Rank: Ace, Suit: SUIT("Z")}), ErrInvalidCard)
+	a.ErrorIs(Validate(&Card{Rank: Ace}), ErrInvalidCard)
+}
@@ -0,0 +1,104 @@
+package card
+
+import (
+	"fmt"
+	"iter"
+	"math/bits"
+	"strings"
+)
+
+// Set is a set of cards held as a 64 bit mask, card c is bit
+// SuitIndexes[c.Suit]*NumRanks + RankIndexes[c.Rank], the order of
+// a fresh deck. Jokers cannot be held.
+type Set uint64
+
+// FullSet holds all fifty-two cards
+const FullSet = Set(1)<<NumCards - 1
+
+// Index returns the bit a card is held at in a Set
+func Index(c *Card) (int, error) {
+	if err := Validate(c); err != nil {
+		return 0, err
+	}
+	if c.IsJoker() {
+		return 0, fmt.Errorf("%w, a joker cannot be held in a set", ErrInvalidCard)
+	}
+	return SuitIndexes[c.Suit]*NumRanks + RankIndexes[c.Rank], nil
+}
+
+// NewSet returns the set of the given cards, it returns an error
+// wrapping ErrDuplicateCard if a card is given twice
+func NewSet(cards ...*Card) (Set, error) {
+	var s Set
+	for _, c := range cards {
+		i, err := Index(c)
+		if err != nil {
+			return 0, err
+		}
+		if s&(1<<i) != 0 {
+			return 0, fmt.Errorf("%w %s", ErrDuplicateCard, c)
+		}
+		s |= 1 << i
+	}
+	return s, nil
+}
+
+// Contains returns true if c is in the set
+func (s Set) Contains(c *Card) bool {
+	i, err := Index(c)
+	return err == nil && s&(1<<i) != 0
+}
+
+// ContainsAll returns true if every card of o is in the set
+func (s Set) ContainsAll(o Set) bool {
+	return s&o == o
+}
+
+// Union returns the cards in either set
+func (s Set) Union(o Set) Set {
+	return s | o
+}
+
+// Intersect returns the cards in both sets
+func (s Set) Intersect(o Set) Set {
+	return s & o
+}
+
+// Difference returns the cards in s but not o
+func (s Set) Difference(o Set) Set {
+	return s &^ o
+}
+
+// Count returns the number of cards in the set
+func (s Set) Count() int {
+	return bits.OnesCount64(uint64(s))
+}
+
+// All returns the cards in the set in the order of a fresh deck
+func (s Set) All() iter.Seq[*Card] {
+	return func(yield func(*Card) bool) {
+		for m := uint64(s & FullSet); m != 0; m &= m - 1 {
+			i := bits.TrailingZeros64(m)
+			if !yield(New(Ranks[i%NumRanks], Suits[i/NumRanks])) {
+				return
+			}
+		}
+	}
+}
+
+// Cards returns the cards in the set in the order of a fresh deck
+func (s Set) Cards() []*Card {
+	cards := make([]*Card, 0, s.Count())
+	for c := range s.All() {
+		cards = append(cards, c)
+	}
+	return cards
+}
+
+func (s Set) String() string {
+	var names []string
+	for c := range s.All() {
+		names = append(names, c.String())
+	}
+	return "{" + strings.Join(names, " ") + "}"
+}
@@ -0,0 +1,87 @@
+// Command equitydb precomputes the flop and turn equity tables
+// read back by headsup.ReadEquityDB.
+//
+// Every canonical board of the chosen streets is generated, each hand
+// against a random hand or each pair of hands against one another:
+//
+//	go run ./cmd/equitydb -street turn -o turn.gseq
+//	go run ./cmd/equitydb -street flop -vs hand -boards 10 -o flop.gseq
+//
+// Every spot plays out each deal left, around a million on the flop and
+// fifty thousand on the turn against a random hand, so a single turn
+// board takes minutes on one CPU and full tables take days even on many.
+// Limit the number of boards with -boards to build part of a table.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/aultimus/gosouth/hand"
+	"github.com/aultimus/gosouth/headsup"
+)
+
+// streets maps each street to its board size
+var streets = map[string]int{
+	"flop": 3,
+	"turn": 4,
+}
+
+func main() {
+	out := flag.String("o", "equity.gseq", "file to write the tables to")
+	street := flag.String("street", "all", "street to generate, flop, turn or all")
+	vs := flag.String("vs", "random", "opponent, random or hand")
+	numBoards := flag.Int("boards", 0, "boards per street to generate, 0 for all")
+	flag.Parse()
+
+	if err := run(*out, *street, *vs, *numBoards); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+func run(out, street, vs string, numBoards int) error {
+	var sizes []int
+	switch street {
+	case "all":
+		sizes = []int{streets["flop"], streets["turn"]}
+	case "flop", "turn":
+		sizes = []int{streets[street]}
+	default:
+		return fmt.Errorf("unknown street %q", street)
+	}
+	if vs != "random" && vs != "hand" {
+		return fmt.Errorf("unknown opponent %q", vs)
+	}
+	if numBoards < 0 {
+		return fmt.Errorf("cannot generate %d boards", numBoards)
+	}
+
+	var boards []hand.Hand
+	for _, size := range sizes {
+		b := headsup.CanonicalBoards(size)
+		if numBoards > 0 && numBoards < len(b) {
+			b = b[:numBoards]
+		}
+		boards = append(boards, b...)
+	}
+	db, err := headsup.GenerateEquityDB(headsup.BoardSpots(boards, vs == "random"))
+	if err != nil {
+		return err
+	}
+
+	f, err := os.Create(out)
+	if err != nil {
+		return err
+	}
+	if err := headsup.WriteEquityDB(f, db); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	fmt.Printf("wrote %d spots on %d boards to %s\n", db.Len(), len(boards), out)
+	return nil
+}
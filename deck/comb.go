@@ -0,0 +1,164 @@
+package deck
+
+import (
+	"fmt"
+	"iter"
+)
+
+// Binomial returns n choose k, the number of combinations
+// of k cards from n. It overflows for n much past 60.
+func Binomial(n, k int) uint64 {
+	if k < 0 || k > n {
+		return 0
+	}
+	k = min(k, n-k)
+	r := uint64(1)
+	for i := 0; i < k; i++ {
+		r = r * uint64(n-i) / uint64(i+1)
+	}
+	return r
+}
+
+// Rank returns the position of a combination, given as increasing indexes
+// into n cards, in the lexicographic order Combinations visits them in
+func Rank(indices []int, n int) uint64 {
+	k := len(indices)
+	rank := uint64(0)
+	prev := -1
+	for i, c := range indices {
+		for j := prev + 1; j < c; j++ {
+			rank += Binomial(n-1-j, k-1-i)
+		}
+		prev = c
+	}
+	return rank
+}
+
+// Unrank fills indices, whose length is the combination size, with the
+// combination of indexes into n cards at the given rank
+func Unrank(rank uint64, n int, indices []int) error {
+	k := len(indices)
+	if rank >= Binomial(n, k) {
+		return fmt.Errorf("rank %d is out of range for %d choose %d", rank, n, k)
+	}
+	j := 0
+	for i := range indices {
+		for {
+			count := Binomial(n-1-j, k-1-i)
+			if rank < count {
+				break
+			}
+			rank -= count
+			j++
+		}
+		indices[i] = j
+		j++
+	}
+	return nil
+}
+
+// Partition splits the ranks of the combinations of k cards from n into
+// parts ranges of near equal size, for iterating over them in parallel
+func Partition(n, k, parts int) [][2]uint64 {
+	total := Binomial(n, k)
+	var ranges [][2]uint64
+	for p := 0; p < parts; p++ {
+		from := total * uint64(p) / uint64(parts)
+		to := total * uint64(p+1) / uint64(parts)
+		ranges = append(ranges, [2]uint64{from, to})
+	}
+	return ranges
+}
+
+// Combinations iterates over the combinations of k cards from a deck in
+// lexicographic order of their indexes. It reuses one slice for every
+// combination so does not allocate per step, a combination must be copied
+// to be kept past the next call to Next.
+type Combinations struct {
+	d       Deck
+	indices []int
+	comb    Deck
+	rank    uint64
+	end     uint64
+	started bool
+}
+
+// NewCombinations returns an iterator over every combination
+// of k cards from d
+func NewCombinations(d Deck, k int) *Combinations {
+	c, _ := NewCombinationsRange(d, k, 0, Binomial(len(d), k))
+	return c
+}
+
+// NewCombinationsRange returns an iterator over the combinations of k
+// cards from d with ranks from up to but not including to
+func NewCombinationsRange(d Deck, k int, from, to uint64) (*Combinations, error) {
+	if k < 0 || k > len(d) {
+		return &Combinations{}, nil
+	}
+	if total := Binomial(len(d), k); from > to || to > total {
+		return nil, fmt.Errorf("ranks %d to %d are out of range for %d choose %d", from, to, len(d), k)
+	}
+	return &Combinations{
+		d:       d,
+		indices: make([]int, k),
+		comb:    make(Deck, k),
+		rank:    from,
+		end:     to,
+	}, nil
+}
+
+// Next moves to the next combination, returning false once there are none
+func (c *Combinations) Next() bool {
+	if c.rank >= c.end {
+		return false
+	}
+	k, n := len(c.indices), len(c.d)
+	first := 0
+	if !c.started {
+		c.started = true
+		// the range was checked so the rank is valid
+		_ = Unrank(c.rank, n, c.indices)
+	} else {
+		i := k - 1
+		for ; c.indices[i] == i+n-k; i-- {
+		}
+		c.indices[i]++
+		for j := i + 1; j < k; j++ {
+			c.indices[j] = c.indices[j-1] + 1
+		}
+		first = i
+	}
+	for i := first; i < k; i++ {
+		c.comb[i] = c.d[c.indices[i]]
+	}
+	c.rank++
+	return true
+}
+
+// Comb returns the current combination, valid until the next call to Next
+func (c *Combinations) Comb() Deck {
+	return c.comb
+}
+
+// Indices returns the indexes into the deck of the current combination
+func (c *Combinations) Indices() []int {
+	return c.indices
+}
+
+// Rank returns the rank of the current combination
+func (c *Combinations) Rank() uint64 {
+	return c.rank - 1
+}
+
+// All returns the remaining combinations for use with range,
+// the slice yielded is reused as with Comb
+func (c *Combinations) All() iter.Seq[Deck] {
+	return func(yield func(Deck) bool) {
+		for c.Next() {
+			if !yield(c.comb) {
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,117 @@
+package deck
+
+import (
+	"fmt"
+
+	"github.com/aultimus/gosouth/card"
+)
+
+// Dealer deals cards from the top of a stub, keeping the burnt
+// and mucked cards to one side
+type Dealer struct {
+	stub  Deck
+	burnt Deck
+	muck  Deck
+}
+
+// NewDealer returns a dealer dealing from the top of d,
+// d itself is left intact
+func NewDealer(d Deck) *Dealer {
+	return &Dealer{stub: append(Deck(nil), d...)}
+}
+
+// NewShoe returns numDecks fresh decks one after another,
+// as dealt from a casino shoe
+func NewShoe(numDecks int) Deck {
+	var d Deck
+	for i := 0; i < numDecks; i++ {
+		d = append(d, New()...)
+	}
+	return d
+}
+
+// Remaining returns the number of cards left in the stub
+func (d *Dealer) Remaining() int {
+	return len(d.stub)
+}
+
+// Burnt returns the burnt cards in the order they were burnt
+func (d *Dealer) Burnt() Deck {
+	return d.burnt
+}
+
+// Mucked returns the cards returned to the muck
+func (d *Dealer) Mucked() Deck {
+	return d.muck
+}
+
+func (d *Dealer) check(n int) error {
+	if n < 0 {
+		return fmt.Errorf("cannot deal %d cards", n)
+	}
+	if n > len(d.stub) {
+		return fmt.Errorf("%w: cannot deal %d cards, only %d remain", ErrExhausted, n, len(d.stub))
+	}
+	return nil
+}
+
+// Peek returns the top n cards of the stub without dealing them
+func (d *Dealer) Peek(n int) (Deck, error) {
+	if err := d.check(n); err != nil {
+		return nil, err
+	}
+	return append(Deck(nil), d.stub[:n]...), nil
+}
+
+// Deal deals the top n cards of the stub, returning an
+// error wrapping ErrExhausted if fewer remain
+func (d *Dealer) Deal(n int) (Deck, error) {
+	cards, err := d.Peek(n)
+	if err != nil {
+		return nil, err
+	}
+	d.stub = d.stub[n:]
+	return cards, nil
+}
+
+// Burn deals the top card of the stub face down to the burn pile
+func (d *Dealer) Burn() error {
+	c, err := d.Deal(1)
+	if err != nil {
+		return err
+	}
+	d.burnt = append(d.burnt, c...)
+	return nil
+}
+
+// DealRound deals n cards to each of numSeats seats one card at a time
+// in rotation starting with seat 0. Nothing is dealt if the stub is short.
+func (d *Dealer) DealRound(numSeats, n int) ([]Deck, error) {
+	if numSeats < 0 || n < 0 {
+		return nil, fmt.Errorf("cannot deal %d cards to %d seats", n, numSeats)
+	}
+	cards, err := d.Deal(numSeats * n)
+	if err != nil {
+		return nil, err
+	}
+	seats := make([]Deck, numSeats)
+	for i, c := range cards {
+		seats[i%numSeats] = append(seats[i%numSeats], c)
+	}
+	return seats, nil
+}
+
+// Muck returns cards to the muck
+func (d *Dealer) Muck(cards ...*card.Card) {
+	d.muck = append(d.muck, cards...)
+}
+
+// Reshuffle shuffles the muck with s, or Crypto if s is nil,
+// and places it beneath the stub
+func (d *Dealer) Reshuffle(s Shuffler) {
+	if s == nil {
+		s = Crypto
+	}
+	d.stub = append(d.stub, s.Shuffle(d.muck)...)
+	d.muck = nil
+}
@@ -1,14 +1,19 @@
 package deck
 
 import (
+	"errors"
 	"fmt"
-	"math/rand"
-	"reflect"
-	"time"
 
 	"github.com/aultimus/gosouth/card"
 )
 
+var (
+	// ErrNotInDeck is wrapped by errors for a card missing from a deck
+	ErrNotInDeck = errors.New("card not in deck")
+	// ErrExhausted is wrapped by errors for a deal larger than the cards left
+	ErrExhausted = errors.New("not enough cards left")
+)
+
 // Deck represents a deck of cards
 type Deck []*card.Card
 
@@ -23,90 +28,107 @@ func New() Deck {
 	return d
 }
 
-// NewShuffled returns a sorted deck
-func NewShuffled() Deck {
-	return knuthShuffle(New())
+// NewWithJokers returns a fresh unsorted deck of fifty-two cards
+// followed by numJokers jokers
+func NewWithJokers(numJokers int) Deck {
+	d := New()
+	for i := 0; i < numJokers; i++ {
+		d = append(d, card.NewJoker())
+	}
+	return d
 }
 
-// SeedWithNow seeds the random number gen
-// with the current time (millis)
-func SeedWithNow() {
-	rand.Seed(time.Now().UTC().UnixNano())
+// NewShuffled returns a deck shuffled by Crypto
+func NewShuffled() Deck {
+	return Crypto.Shuffle(New())
 }
 
-// Combs returns all possible combinations of
-// dealing k cards from Deck d
-// from len(d) pick k
-func Combs(d Deck, k int, c chan Deck) {
-	pool := d
-	n := len(pool)
-
-	if k > n {
-		return
-	}
-
-	indices := make([]int, k)
-	for i := range indices {
-		indices[i] = i
-	}
+// NewShuffledWith returns a deck shuffled by s
+func NewShuffledWith(s Shuffler) Deck {
+	return s.Shuffle(New())
+}
 
-	result := make(Deck, k)
-	for i, el := range indices {
-		result[i] = pool[el]
-	}
-	c <- result
+// Shuffle shuffles the given deck in place with Crypto and returns it
+func Shuffle(d Deck) Deck {
+	return Crypto.Shuffle(d)
+}
 
-	for {
-		i := k - 1
-		for ; i >= 0 && indices[i] == i+n-k; i-- {
-		}
-		if i < 0 {
-			close(c)
-			return
-		}
-		indices[i]++
-		for j := i + 1; j < k; j++ {
-			indices[j] = indices[j-1] + 1
-		}
+// SeedWithNow used to seed the global random number gen.
+//
+// Deprecated: shuffles no longer use the global source,
+// use NewSeeded for reproducible shuffles.
+func SeedWithNow() {
+}
 
-		for ; i < len(indices); i++ {
-			result[i] = pool[indices[i]]
-		}
-		c <- result
+// Combs sends all possible combinations of
+// dealing k cards from Deck d down c then closes it,
+// each combination is a new slice
+//
+// Deprecated: use NewCombinations, which does not allocate per combination.
+func Combs(d Deck, k int, c chan Deck) {
+	defer close(c)
+	for comb := range NewCombinations(d, k).All() {
+		c <- append(Deck(nil), comb...)
 	}
 }
 
 // Remove removes a card from the given deck,
-// returns an error if given card is not in deck
+// returns an error wrapping ErrNotInDeck if given card is not in deck
 func Remove(d Deck, c *card.Card) (Deck, error) {
 	for i, v := range d {
-		if reflect.DeepEqual(c, v) {
+		if *c == *v {
 			// Garbage collection probs?
 			// https://github.com/golang/go/wiki/SliceTricks
 			d = append(d[:i], d[i+1:]...)
 			return d, nil
 		}
 	}
-	return d, fmt.Errorf("card %s is not in deck", c)
+	return d, fmt.Errorf("%w: %s", ErrNotInDeck, c)
 }
 
-// RemoveMultiple removes multiple cards from
+// RemoveMultiple removes multiple cards from a deck,
+// the error joins the errors for every card not in it
 func RemoveMultiple(d Deck, c []*card.Card) (Deck, error) {
-	var err error
+	var errs []error
 	for _, v := range c {
-		d, err = Remove(d, v)
+		var err error
+		if d, err = Remove(d, v); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return d, errors.Join(errs...)
+}
+
+// Set returns the set of cards in the deck, it returns an
+// error if the deck holds a joker or a card twice
+func (d Deck) Set() (card.Set, error) {
+	return card.NewSet(d...)
+}
+
+// FromSet returns the cards in s as a deck in the order of a fresh deck
+func FromSet(s card.Set) Deck {
+	return s.Cards()
+}
+
+// Without returns a new deck of the cards of d not in s, d is left intact
+func Without(d Deck, s card.Set) Deck {
+	var out Deck
+	for _, c := range d {
+		if !s.Contains(c) {
+			out = append(out, c)
+		}
 	}
-	return d, err
+	return out
 }
 
 // knuthShuffle is an implementation of the
 // Knuth/Fisher-Yates shuffle, in place, O(n)
 // https://en.wikipedia.org/wiki/Fisher%E2%80%93Yates_shuffle
-// Remember to seed prior to calling
-func knuthShuffle(a Deck) Deck {
+func knuthShuffle(a Deck, rng RNG) Deck {
 	n := len(a)
-	for i := 0; i < n-2; i++ {
-		j := rand.Intn(n - i)
+	// the last position is left with the one remaining card
+	for i := 0; i < n-1; i++ {
+		j := rng.Intn(n - i)
 		a[i], a[i+j] = a[i+j], a[i]
 	}
 	return a
@@ -2,7 +2,9 @@ package deck
 
 import (
 	"fmt"
+	"math/rand"
 	"reflect"
+	"strings"
 	"testing"
 
 	"github.com/aultimus/gosouth/card"
@@ -19,6 +21,48 @@ func TestShuffle(t *testing.T) {
 	}
 }
 
+func TestSeeded(t *testing.T) {
+	a := assert.New(t)
+	d := NewShuffledWith(NewSeeded(42))
+	a.Equal(d, NewShuffledWith(NewSeeded(42)))
+	a.NotEqual(d, NewShuffledWith(NewSeeded(43)))
+	a.NotEqual(New(), d)
+	a.ElementsMatch(New(), d)
+
+	// a shuffler carries on from where it left off
+	s := NewSeeded(42)
+	a.Equal(d, s.Shuffle(New()))
+	a.NotEqual(d, s.Shuffle(New()))
+
+	// seeded shuffles match math/rand with the same seed
+	d = Knuth{RNG: rand.New(rand.NewSource(42))}.Shuffle(New())
+	a.Equal(NewShuffledWith(NewSeeded(42)), d)
+}
+
+func TestShuffleLastCards(t *testing.T) {
+	a := assert.New(t)
+	// the last two cards must be able to swap
+	s := NewSeeded(1)
+	swapped := 0
+	for i := 0; i < 100; i++ {
+		d := New()[:2]
+		first := d[0]
+		if s.Shuffle(d)[0] != first {
+			swapped++
+		}
+	}
+	a.True(swapped > 30 && swapped < 70)
+	a.Empty(s.Shuffle(Deck{}))
+}
+
+func TestCrypto(t *testing.T) {
+	a := assert.New(t)
+	d := NewShuffledWith(Crypto)
+	a.ElementsMatch(New(), d)
+	a.NotEqual(NewShuffled(), NewShuffled())
+	a.Empty(Crypto.Shuffle(nil))
+}
+
 func TestRemove(t *testing.T) {
 	a := assert.New(t)
 	var err error
@@ -46,3 +90,223 @@ func TestCombs(t *testing.T) {
 	}
 	a.Equal(total, count)
 }
+
+func TestCombsTooMany(t *testing.T) {
+	a := assert.New(t)
+	// the channel is closed even when there is nothing to send
+	c := make(chan Deck)
+	go Combs(New()[:3], 5, c)
+	count := 0
+	for range c {
+		count++
+	}
+	a.Equal(0, count)
+}
+
+func TestNewWithJokers(t *testing.T) {
+	a := assert.New(t)
+	d := NewWithJokers(2)
+	a.Equal(54, len(d))
+	a.True(d[52].IsJoker())
+	a.True(d[53].IsJoker())
+	a.False(d[51].IsJoker())
+}
+
+func TestFair(t *testing.T) {
+	a := assert.New(t)
+	f := Fair{ServerSeed: []byte("server seed"), ClientSeeds: []string{"alice", "bob"}, Nonce: 1}
+	commitment := f.Commitment()
+	d := f.Deck()
+	a.ElementsMatch(New(), d)
+	a.Equal(d, f.Deck())
+	// the order is fixed by the seeds, a change to the
+	// derivation would break verification of past hands
+	a.Equal("[2S 8S 6H AC QD]", fmt.Sprint(d[:5]))
+	a.Equal("a4e53dc2f480b8fce6fe688b1317658b446299df23ad533394406427c8c19557", commitment)
+
+	a.NoError(Verify(commitment, f, d))
+	a.NoError(Verify(commitment, f, d[:9]))
+	a.Error(Verify(commitment, f, append(d, d[0])))
+	tampered := append(Deck(nil), d...)
+	tampered[3], tampered[4] = tampered[4], tampered[3]
+	a.Error(Verify(commitment, f, tampered))
+
+	// a different server seed cannot be passed off under the commitment
+	other := f
+	other.ServerSeed = []byte("other seed")
+	a.Error(Verify(commitment, other, other.Deck()))
+
+	// each client seed and the nonce change the deck
+	for _, g := range []Fair{
+		{ServerSeed: f.ServerSeed, ClientSeeds: []string{"alice", "bobb"}, Nonce: 1},
+		{ServerSeed: f.ServerSeed, ClientSeeds: []string{"aliceb", "ob"}, Nonce: 1},
+		{ServerSeed: f.ServerSeed, ClientSeeds: []string{"alice", "bob"}, Nonce: 2},
+	} {
+		a.NotEqual(fmt.Sprint(d), fmt.Sprint(g.Deck()))
+		a.Error(Verify(commitment, g, d))
+	}
+
+	seed, err := NewServerSeed()
+	a.NoError(err)
+	a.Equal(32, len(seed))
+}
+
+func TestCombinations(t *testing.T) {
+	a := assert.New(t)
+	d := New()[:6]
+	combs := NewCombinations(d, 3)
+	var seen []Deck
+	for combs.Next() {
+		a.Equal(uint64(len(seen)), combs.Rank())
+		a.Equal(combs.Rank(), Rank(combs.Indices(), len(d)))
+		seen = append(seen, append(Deck(nil), combs.Comb()...))
+	}
+	a.Equal(20, len(seen))
+	a.Equal(uint64(20), Binomial(6, 3))
+	a.Equal(Deck{d[0], d[1], d[2]}, seen[0])
+	a.Equal(Deck{d[3], d[4], d[5]}, seen[19])
+	a.False(combs.Next())
+
+	// the ranges of a partition cover every combination once
+	var parted []Deck
+	for _, r := range Partition(len(d), 3, 3) {
+		combs, err := NewCombinationsRange(d, 3, r[0], r[1])
+		a.NoError(err)
+		for comb := range combs.All() {
+			parted = append(parted, append(Deck(nil), comb...))
+		}
+	}
+	a.Equal(seen, parted)
+
+	_, err := NewCombinationsRange(d, 3, 5, 21)
+	a.Error(err)
+	a.False(NewCombinations(d, 7).Next())
+	empty := NewCombinations(d, 0)
+	a.True(empty.Next())
+	a.Empty(empty.Comb())
+	a.False(empty.Next())
+}
+
+func TestRank(t *testing.T) {
+	a := assert.New(t)
+	a.Equal(uint64(2598960), Binomial(52, 5))
+	indices := make([]int, 5)
+	for _, r := range []uint64{0, 1, 1000, 2598959} {
+		a.NoError(Unrank(r, 52, indices))
+		a.Equal(r, Rank(indices, 52))
+	}
+	a.NoError(Unrank(2598959, 52, indices))
+	a.Equal([]int{47, 48, 49, 50, 51}, indices)
+	a.Error(Unrank(2598960, 52, indices))
+}
+
+func TestCombinationsAllocs(t *testing.T) {
+	a := assert.New(t)
+	combs := NewCombinations(New(), 5)
+	allocs := testing.AllocsPerRun(1000, func() {
+		combs.Next()
+	})
+	a.Equal(0.0, allocs)
+}
+
+func TestDealer(t *testing.T) {
+	a := assert.New(t)
+	d := New()
+	dealer := NewDealer(d)
+	a.Equal(52, dealer.Remaining())
+
+	top, err := dealer.Peek(2)
+	a.NoError(err)
+	a.Equal(52, dealer.Remaining())
+	cards, err := dealer.Deal(2)
+	a.NoError(err)
+	a.Equal(top, cards)
+	a.Equal(Deck{d[0], d[1]}, cards)
+
+	a.NoError(dealer.Burn())
+	a.Equal(Deck{d[2]}, dealer.Burnt())
+
+	// cards go round the seats one at a time
+	seats, err := dealer.DealRound(3, 2)
+	a.NoError(err)
+	a.Equal(Deck{d[3], d[6]}, seats[0])
+	a.Equal(Deck{d[5], d[8]}, seats[2])
+	a.Equal(43, dealer.Remaining())
+
+	_, err = dealer.DealRound(22, 2)
+	a.Error(err)
+	a.Equal(43, dealer.Remaining())
+	_, err = dealer.Deal(43)
+	a.NoError(err)
+	_, err = dealer.Deal(1)
+	a.Error(err)
+	a.Error(dealer.Burn())
+	_, err = dealer.Peek(-1)
+	a.Error(err)
+
+	// the muck is shuffled back beneath the stub
+	dealer.Muck(seats[0]...)
+	a.Equal(2, len(dealer.Mucked()))
+	dealer.Reshuffle(NewSeeded(1))
+	a.Empty(dealer.Mucked())
+	cards, err = dealer.Deal(2)
+	a.NoError(err)
+	a.ElementsMatch(seats[0], cards)
+
+	// the caller's deck is left intact
+	a.Equal(New()[0].String(), d[0].String())
+	a.Equal(52, len(d))
+}
+
+func TestShoe(t *testing.T) {
+	a := assert.New(t)
+	shoe := NewShoe(6)
+	a.Equal(312, len(shoe))
+	a.Equal(shoe[0].String(), shoe[52].String())
+	a.NotSame(shoe[0], shoe[52])
+	dealer := NewDealer(NewSeeded(1).Shuffle(shoe))
+	a.Equal(312, dealer.Remaining())
+}
+
+func TestSet(t *testing.T) {
+	a := assert.New(t)
+	d := New()
+	s, err := d.Set()
+	a.NoError(err)
+	a.Equal(card.FullSet, s)
+	a.Equal(d, FromSet(s))
+
+	hole, err := Deck{d[0], d[13]}.Set()
+	a.NoError(err)
+	rest := Without(d, hole)
+	a.Equal(50, len(rest))
+	a.Equal(52, len(d))
+	a.Equal(d[1], rest[0])
+
+	_, err = NewWithJokers(1).Set()
+	a.Error(err)
+	_, err = Deck{d[0], d[0]}.Set()
+	a.Error(err)
+}
+
+func TestRemoveErrors(t *testing.T) {
+	a := assert.New(t)
+	d := New()
+	as, kh := card.New(card.Ace, card.Spades), card.New(card.King, card.Hearts)
+	d, err := Remove(d, as)
+	a.NoError(err)
+	_, err = Remove(d, as)
+	a.ErrorIs(err, ErrNotInDeck)
+
+	// every missing card is reported, not only the last
+	d, err = RemoveMultiple(d, []*card.Card{as, kh, as})
+	a.ErrorIs(err, ErrNotInDeck)
+	a.Equal(2, strings.Count(err.Error(), "AS"))
+	a.Equal(50, len(d))
+	_, err = RemoveMultiple(d, []*card.Card{card.New(card.Two, card.Clubs)})
+	a.NoError(err)
+
+	_, err = NewDealer(d[:1]).Deal(2)
+	a.ErrorIs(err, ErrExhausted)
+	a.ErrorIs(NewDealer(nil).Burn(), ErrExhausted)
+}
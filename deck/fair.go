@@ -0,0 +1,116 @@
+package deck
+
+import (
+	"crypto/hmac"
+	crand "crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"math"
+)
+
+// serverSeedSize is the number of random bytes in a server seed
+const serverSeedSize = 32
+
+// Fair is a provably fair deal. The server publishes the Commitment to
+// its seed before the hand, players add their own seeds, and once the
+// hand is over the server seed is revealed so anyone can Verify the deck.
+// Nonce numbers the hands dealt with the same seeds.
+type Fair struct {
+	ServerSeed  []byte
+	ClientSeeds []string
+	Nonce       uint64
+}
+
+// NewServerSeed returns a random server seed
+func NewServerSeed() ([]byte, error) {
+	seed := make([]byte, serverSeedSize)
+	if _, err := crand.Read(seed); err != nil {
+		return nil, err
+	}
+	return seed, nil
+}
+
+// Commitment returns the hex SHA-256 hash of the server seed,
+// published before the hand is dealt
+func (f Fair) Commitment() string {
+	sum := sha256.Sum256(f.ServerSeed)
+	return hex.EncodeToString(sum[:])
+}
+
+// key combines the seeds and nonce, an HMAC-SHA256 keyed by the server
+// seed over the nonce and each client seed prefixed by its length
+func (f Fair) key() []byte {
+	mac := hmac.New(sha256.New, f.ServerSeed)
+	var b [8]byte
+	binary.BigEndian.PutUint64(b[:], f.Nonce)
+	mac.Write(b[:])
+	for _, s := range f.ClientSeeds {
+		binary.BigEndian.PutUint64(b[:], uint64(len(s)))
+		mac.Write(b[:])
+		mac.Write([]byte(s))
+	}
+	return mac.Sum(nil)
+}
+
+// Shuffle shuffles the given deck in place and returns it. The order
+// depends only on the seeds and nonce, every call shuffles the same way.
+func (f Fair) Shuffle(d Deck) Deck {
+	return knuthShuffle(d, &fairRNG{key: f.key()})
+}
+
+// Deck returns a fresh deck shuffled by the seeds
+func (f Fair) Deck() Deck {
+	return f.Shuffle(New())
+}
+
+// Verify checks that a revealed server seed matches the commitment
+// published before the hand and that the deck, or the cards dealt from
+// the top of it, came from the seeds
+func Verify(commitment string, f Fair, d Deck) error {
+	if f.Commitment() != commitment {
+		return fmt.Errorf("server seed does not match commitment %s", commitment)
+	}
+	want := f.Deck()
+	if len(d) > len(want) {
+		return fmt.Errorf("deck of %d cards is larger than a deck of %d", len(d), len(want))
+	}
+	for i := range d {
+		if d[i].String() != want[i].String() {
+			return fmt.Errorf("card %d is %s, the seeds deal %s", i+1, d[i], want[i])
+		}
+	}
+	return nil
+}
+
+// fairRNG draws numbers from SHA-256 of the key and a counter
+type fairRNG struct {
+	key     []byte
+	counter uint64
+	block   []byte
+}
+
+func (r *fairRNG) uint64() uint64 {
+	if len(r.block) < 8 {
+		var b [8]byte
+		binary.BigEndian.PutUint64(b[:], r.counter)
+		r.counter++
+		sum := sha256.Sum256(append(append([]byte(nil), r.key...), b[:]...))
+		r.block = sum[:]
+	}
+	v := binary.BigEndian.Uint64(r.block)
+	r.block = r.block[8:]
+	return v
+}
+
+// Intn returns a uniform random number in [0, n), values
+// that would bias the result towards small numbers are redrawn
+func (r *fairRNG) Intn(n int) int {
+	limit := math.MaxUint64 - math.MaxUint64%uint64(n)
+	for {
+		if v := r.uint64(); v < limit {
+			return int(v % uint64(n))
+		}
+	}
+}
@@ -0,0 +1,55 @@
+package deck
+
+import (
+	crand "crypto/rand"
+	"math/big"
+	"math/rand"
+)
+
+// RNG is a source of random numbers, *rand.Rand is one
+type RNG interface {
+	// Intn returns a uniform random number in [0, n)
+	Intn(n int) int
+}
+
+// Shuffler puts a deck in random order
+type Shuffler interface {
+	// Shuffle shuffles the given deck in place and returns it
+	Shuffle(d Deck) Deck
+}
+
+// Knuth is a Knuth/Fisher-Yates shuffle drawing from RNG
+type Knuth struct {
+	RNG RNG
+}
+
+// Shuffle shuffles the given deck in place and returns it
+func (k Knuth) Shuffle(d Deck) Deck {
+	return knuthShuffle(d, k.RNG)
+}
+
+// NewSeeded returns a deterministic shuffler, shufflers made with the
+// same seed shuffle in the same order. It is not safe for concurrent use,
+// give each table or simulation its own.
+func NewSeeded(seed int64) Shuffler {
+	return Knuth{RNG: rand.New(rand.NewSource(seed))}
+}
+
+// cryptoRNG draws from crypto/rand
+type cryptoRNG struct{}
+
+func (cryptoRNG) Intn(n int) int {
+	v, err := crand.Int(crand.Reader, big.NewInt(int64(n)))
+	if err != nil {
+		// the operating system has no randomness to give
+		panic(err)
+	}
+	return int(v.Int64())
+}
+
+// CryptoRNG draws from crypto/rand, it needs no
+// seeding and is safe for concurrent use
+var CryptoRNG RNG = cryptoRNG{}
+
+// Crypto is a shuffler drawing from CryptoRNG
+var Crypto Shuffler = Knuth{RNG: CryptoRNG}
@@ -0,0 +1,184 @@
+package draw
+
+import (
+	"fmt"
+
+	"github.com/aultimus/gosouth/deck"
+	"github.com/aultimus/gosouth/hand"
+)
+
+const sizeHand = 5
+
+// Game describes the rules of a draw poker variant
+type Game struct {
+	Name     string
+	HandSize int
+	Draws    int
+	Eval     Evaluator
+}
+
+// FiveCardDraw is five card draw played for high with a single draw
+var FiveCardDraw = Game{
+	Name:     "Five Card Draw",
+	HandSize: sizeHand,
+	Draws:    1,
+	Eval:     High,
+}
+
+// TripleDraw is 2-7 lowball with three drawing rounds
+var TripleDraw = Game{
+	Name:     "2-7 Triple Draw",
+	HandSize: sizeHand,
+	Draws:    3,
+	Eval:     DeuceToSeven,
+}
+
+// SingleDraw is 2-7 lowball with a single draw
+var SingleDraw = Game{
+	Name:     "2-7 Single Draw",
+	HandSize: sizeHand,
+	Draws:    1,
+	Eval:     DeuceToSeven,
+}
+
+// AceToFiveTripleDraw is California lowball with three drawing rounds
+var AceToFiveTripleDraw = Game{
+	Name:     "A-5 Triple Draw",
+	HandSize: sizeHand,
+	Draws:    3,
+	Eval:     AceToFive,
+}
+
+// Table represents a single deal of a draw game.
+// Hands are indexed by seat, seat 0 being first to act.
+type Table struct {
+	Game  Game
+	Hands []hand.Hand
+	// Shuffler reshuffles the muck, deck.Crypto if nil
+	Shuffler deck.Shuffler
+	dealer   *deck.Dealer
+	round    int
+}
+
+// Deal deals a new draw game to numPlayers players from deck d,
+// one card at a time in rotation starting with seat 0
+func Deal(g Game, d deck.Deck, numPlayers int) (*Table, error) {
+	if numPlayers < 2 {
+		return nil, fmt.Errorf("a draw game needs at least 2 players, not %d", numPlayers)
+	}
+	if g.HandSize*numPlayers > len(d) {
+		return nil, fmt.Errorf("cannot deal %d cards to %d players from a deck of %d cards",
+			g.HandSize, numPlayers, len(d))
+	}
+	t := &Table{
+		Game:   g,
+		Hands:  make([]hand.Hand, numPlayers),
+		dealer: deck.NewDealer(d),
+	}
+	dealt, err := t.dealer.DealRound(numPlayers, g.HandSize)
+	if err != nil {
+		return nil, err
+	}
+	for p, h := range dealt {
+		t.Hands[p] = hand.Hand(h)
+	}
+	return t, nil
+}
+
+func (t *Table) shuffler() deck.Shuffler {
+	if t.Shuffler == nil {
+		return deck.Crypto
+	}
+	return t.Shuffler
+}
+
+// Round returns the number of drawing rounds completed
+func (t *Table) Round() int {
+	return t.round
+}
+
+// DrawsRemaining returns the number of drawing rounds left to play
+func (t *Table) DrawsRemaining() int {
+	return t.Game.Draws - t.round
+}
+
+// Stub returns the number of undealt cards
+func (t *Table) Stub() int {
+	return t.dealer.Remaining()
+}
+
+// Muck returns the number of discarded cards not yet reshuffled
+func (t *Table) Muck() int {
+	return len(t.dealer.Mucked())
+}
+
+// Draw plays a drawing round. discards holds for each seat the indexes
+// of the cards in that seat's hand to be replaced, a nil or empty
+// entry stands pat. Seats draw in order, if the stub runs out the muck
+// is shuffled and placed beneath it. A seat's own discards from this
+// round are not part of the muck it may draw from. If any seat cannot
+// draw, no seat does and the round is not played.
+func (t *Table) Draw(discards [][]int) error {
+	if t.DrawsRemaining() <= 0 {
+		return fmt.Errorf("all %d drawing rounds of %s have been played",
+			t.Game.Draws, t.Game.Name)
+	}
+	if len(discards) != len(t.Hands) {
+		return fmt.Errorf("expected discards for %d seats, got %d",
+			len(t.Hands), len(discards))
+	}
+	// every seat's discards go back to the muck, so the cards out of the
+	// players' hands stay the same through the round and each seat can
+	// be checked against them before any card changes hands
+	available := t.dealer.Remaining() + len(t.dealer.Mucked())
+	for p, d := range discards {
+		if err := validDiscards(t.Hands[p], d); err != nil {
+			return fmt.Errorf("seat %d: %s", p, err)
+		}
+		if len(d) > available {
+			return fmt.Errorf("seat %d: cannot draw %d cards, only %d are out of play",
+				p, len(d), available)
+		}
+	}
+
+	for p, d := range discards {
+		if len(d) == 0 {
+			continue
+		}
+		if t.dealer.Remaining() < len(d) {
+			t.dealer.Reshuffle(t.shuffler())
+		}
+		// the draws were checked to fit
+		drawn, _ := t.dealer.Deal(len(d))
+		var mucked deck.Deck
+		h := append(hand.Hand(nil), t.Hands[p]...)
+		for j, i := range d {
+			mucked = append(mucked, h[i])
+			h[i] = drawn[j]
+		}
+		t.Hands[p] = h
+		t.dealer.Muck(mucked...)
+	}
+	t.round++
+	return nil
+}
+
+// Showdown evaluates the hands with the game's evaluator and returns
+// the winning index/ drawing indexes
+func (t *Table) Showdown() ([]int, error) {
+	return t.Game.Eval(t.Hands)
+}
+
+func validDiscards(h hand.Hand, d []int) error {
+	seen := make(map[int]bool)
+	for _, i := range d {
+		if i < 0 || i >= len(h) {
+			return fmt.Errorf("discard index %d out of range for hand of %d cards", i, len(h))
+		}
+		if seen[i] {
+			return fmt.Errorf("card at index %d discarded twice", i)
+		}
+		seen[i] = true
+	}
+	return nil
+}
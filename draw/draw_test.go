@@ -0,0 +1,205 @@
+package draw
+
+import (
+	"testing"
+
+	"github.com/aultimus/gosouth/card"
+	"github.com/aultimus/gosouth/deck"
+	"github.com/aultimus/gosouth/hand"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDeal(t *testing.T) {
+	a := assert.New(t)
+	d := deck.New()
+	tbl, err := Deal(FiveCardDraw, d, 3)
+	a.NoError(err)
+	a.Equal(3, len(tbl.Hands))
+	for _, h := range tbl.Hands {
+		a.Equal(5, len(h))
+	}
+	// dealt in rotation
+	a.Equal(d[0], tbl.Hands[0][0])
+	a.Equal(d[1], tbl.Hands[1][0])
+	a.Equal(d[3], tbl.Hands[0][1])
+	a.Equal(52-15, tbl.Stub())
+
+	_, err = Deal(FiveCardDraw, d, 11)
+	a.Error(err)
+	_, err = Deal(FiveCardDraw, d, 1)
+	a.Error(err)
+}
+
+func TestDraw(t *testing.T) {
+	a := assert.New(t)
+	d := deck.New()
+	tbl, err := Deal(FiveCardDraw, d, 2)
+	a.NoError(err)
+
+	a.Error(tbl.Draw([][]int{{0}}))
+	a.Error(tbl.Draw([][]int{{0, 0}, nil}))
+	a.Error(tbl.Draw([][]int{{5}, nil}))
+
+	kept := tbl.Hands[0][1]
+	a.NoError(tbl.Draw([][]int{{0, 2}, nil}))
+	a.Equal(d[10], tbl.Hands[0][0])
+	a.Equal(kept, tbl.Hands[0][1])
+	a.Equal(d[11], tbl.Hands[0][2])
+	a.Equal(2, tbl.Muck())
+	a.Equal(52-12, tbl.Stub())
+
+	a.Equal(0, tbl.DrawsRemaining())
+	a.Error(tbl.Draw([][]int{nil, nil}))
+}
+
+func TestDrawReshufflesMuck(t *testing.T) {
+	a := assert.New(t)
+	// a short deck of 14 cards leaves a stub of 4 after dealing two hands
+	d := deck.New()[:14]
+	tbl, err := Deal(TripleDraw, d, 2)
+	a.NoError(err)
+	a.Equal(4, tbl.Stub())
+
+	all := []int{0, 1, 2, 3, 4}
+	a.NoError(tbl.Draw([][]int{{0, 1, 2}, nil}))
+	a.Equal(1, tbl.Stub())
+	a.Equal(3, tbl.Muck())
+
+	// seat 0's draw exhausts the stub, seat 1 draws from the reshuffled muck
+	// which must not include its own discards
+	own := append(hand.Hand(nil), tbl.Hands[1]...)
+	a.NoError(tbl.Draw([][]int{nil, all[:3]}))
+	for _, c := range tbl.Hands[1][:3] {
+		a.NotContains(own, c)
+	}
+	a.Equal(3, tbl.Muck())
+
+	// too many cards are out of play to satisfy a five card draw
+	a.Error(tbl.Draw([][]int{all, all}))
+}
+
+func TestDrawFailsWhole(t *testing.T) {
+	a := assert.New(t)
+	tbl, err := Deal(TripleDraw, deck.New()[:14], 2)
+	a.NoError(err)
+	hands := []hand.Hand{
+		append(hand.Hand(nil), tbl.Hands[0]...),
+		append(hand.Hand(nil), tbl.Hands[1]...),
+	}
+
+	// seat 0 could draw two of the four cards left but seat 1 cannot
+	// draw five, so the round is refused without seat 0 drawing
+	all := []int{0, 1, 2, 3, 4}
+	a.Error(tbl.Draw([][]int{{0, 1}, all}))
+	a.Equal(hands, tbl.Hands)
+	a.Equal(0, tbl.Round())
+	a.Equal(4, tbl.Stub())
+	a.Equal(0, tbl.Muck())
+
+	// the round can still be played
+	a.NoError(tbl.Draw([][]int{{0, 1}, {0, 1, 2, 3}}))
+	a.Equal(1, tbl.Round())
+}
+
+func TestSeededReshuffle(t *testing.T) {
+	a := assert.New(t)
+	var hands [][]hand.Hand
+	for i := 0; i < 2; i++ {
+		tbl, err := Deal(TripleDraw, deck.New()[:14], 2)
+		a.NoError(err)
+		tbl.Shuffler = deck.NewSeeded(7)
+		a.NoError(tbl.Draw([][]int{{0, 1, 2}, nil}))
+		a.NoError(tbl.Draw([][]int{nil, {0, 1, 2}}))
+		hands = append(hands, tbl.Hands)
+	}
+	// the same seed draws the same cards from the reshuffled muck
+	a.Equal(hands[0], hands[1])
+}
+
+func TestDrawNoDuplicates(t *testing.T) {
+	a := assert.New(t)
+	tbl, err := Deal(TripleDraw, deck.NewShuffled(), 6)
+	a.NoError(err)
+	for tbl.DrawsRemaining() > 0 {
+		a.NoError(tbl.Draw([][]int{
+			{0, 1, 2, 3, 4}, {0, 1, 2, 3}, {0, 1, 2, 3, 4}, {1, 2, 3}, {0, 1, 2, 3, 4}, {4},
+		}))
+		seen := make(map[string]bool)
+		for _, h := range tbl.Hands {
+			for _, c := range h {
+				a.False(seen[c.String()], "%s dealt twice", c)
+				seen[c.String()] = true
+			}
+		}
+	}
+	_, err = tbl.Showdown()
+	a.NoError(err)
+}
+
+func mkHand(cards ...string) hand.Hand {
+	var h hand.Hand
+	for _, s := range cards {
+		h = append(h, card.New(card.RANK(s[:1]), card.SUIT(s[1:])))
+	}
+	return h
+}
+
+func TestHigh(t *testing.T) {
+	a := assert.New(t)
+	flushKing := mkHand("KH", "QH", "8H", "4H", "2H")
+	flushAce := mkHand("AS", "JS", "9S", "5S", "3S")
+	w, err := High([]hand.Hand{flushKing, flushAce})
+	a.NoError(err)
+	a.Equal([]int{1}, w)
+
+	wheel := mkHand("AS", "2D", "3C", "4H", "5S")
+	six := mkHand("2S", "3D", "4C", "5H", "6S")
+	w, err = High([]hand.Hand{wheel, six})
+	a.NoError(err)
+	a.Equal([]int{1}, w)
+
+	_, err = High([]hand.Hand{wheel[:4], six})
+	a.Error(err)
+}
+
+func TestDeuceToSeven(t *testing.T) {
+	a := assert.New(t)
+	nuts := mkHand("7S", "5D", "4C", "3H", "2S")
+	sevenSix := mkHand("7D", "6C", "4S", "3D", "2H")
+	wheel := mkHand("AS", "2D", "3C", "4H", "5S")
+	flush := mkHand("8H", "6H", "4H", "3H", "2H")
+	pair := mkHand("2C", "2D", "3S", "4S", "5S")
+
+	w, err := DeuceToSeven([]hand.Hand{sevenSix, nuts})
+	a.NoError(err)
+	a.Equal([]int{1}, w)
+
+	// A-2-3-4-5 is ace high, not a straight
+	w, err = DeuceToSeven([]hand.Hand{wheel, pair})
+	a.NoError(err)
+	a.Equal([]int{0}, w)
+
+	w, err = DeuceToSeven([]hand.Hand{flush, pair})
+	a.NoError(err)
+	a.Equal([]int{1}, w)
+
+	w, err = DeuceToSeven([]hand.Hand{nuts, mkHand("7H", "5C", "4D", "3S", "2D")})
+	a.NoError(err)
+	a.Equal([]int{0, 1}, w)
+}
+
+func TestAceToFive(t *testing.T) {
+	a := assert.New(t)
+	wheel := mkHand("AS", "2S", "3S", "4S", "5S")
+	sixFour := mkHand("6D", "4C", "3H", "2S", "AD")
+	pair := mkHand("AC", "AD", "2S", "3S", "4S")
+	kingHigh := mkHand("KC", "QD", "JS", "9S", "8S")
+
+	w, err := AceToFive([]hand.Hand{sixFour, wheel})
+	a.NoError(err)
+	a.Equal([]int{1}, w)
+
+	w, err = AceToFive([]hand.Hand{pair, kingHigh})
+	a.NoError(err)
+	a.Equal([]int{1}, w)
+}
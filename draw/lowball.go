@@ -0,0 +1,130 @@
+package draw
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/aultimus/gosouth/card"
+	"github.com/aultimus/gosouth/hand"
+)
+
+// Evaluator determines the winner of two to many final hands,
+// returning the winning index/ drawing indexes
+type Evaluator func(hands []hand.Hand) ([]int, error)
+
+// High evaluates hands by standard high poker rules using hand.FormHand
+func High(hands []hand.Hand) ([]int, error) {
+	for _, h := range hands {
+		if _, err := hand.FormHand(h); err != nil {
+			return nil, err
+		}
+	}
+	return hand.Showdown(hands), nil
+}
+
+// DeuceToSeven evaluates hands by Kansas City lowball rules, aces are
+// always high and straights and flushes count against the hand.
+// The best possible hand is 7-5-4-3-2 unsuited.
+func DeuceToSeven(hands []hand.Hand) ([]int, error) {
+	return lowShowdown(hands, false, true)
+}
+
+// AceToFive evaluates hands by California lowball rules, aces are
+// always low and straights and flushes are ignored.
+// The best possible hand is 5-4-3-2-A.
+func AceToFive(hands []hand.Hand) ([]int, error) {
+	return lowShowdown(hands, true, false)
+}
+
+func lowShowdown(hands []hand.Hand, aceLow, countStraights bool) ([]int, error) {
+	var best []int
+	var winners []int
+	for i, h := range hands {
+		if len(h) != sizeHand {
+			return nil, fmt.Errorf("lowball hand should be %d cards, not %d cards",
+				sizeHand, len(h))
+		}
+		k := lowKey(h, aceLow, countStraights)
+		switch {
+		case best == nil || compareKeys(k, best) < 0:
+			best = k
+			winners = []int{i}
+		case compareKeys(k, best) == 0:
+			winners = append(winners, i)
+		}
+	}
+	return winners, nil
+}
+
+// lowKey scores a five card hand as a high hand, hand rank first then
+// card ranks in order of significance. The lowest key wins at lowball.
+func lowKey(h hand.Hand, aceLow, countStraights bool) []int {
+	counts := make(map[int]int)
+	for _, c := range h {
+		counts[lowRankIndex(c.Rank, aceLow)]++
+	}
+	var ranks []int
+	for r := range counts {
+		ranks = append(ranks, r)
+	}
+	// most frequent first, then highest first
+	sort.Slice(ranks, func(i, j int) bool {
+		if counts[ranks[i]] != counts[ranks[j]] {
+			return counts[ranks[i]] > counts[ranks[j]]
+		}
+		return ranks[i] > ranks[j]
+	})
+
+	var rank hand.RANK
+	switch {
+	case counts[ranks[0]] == 4:
+		rank = hand.FourOfAKind
+	case counts[ranks[0]] == 3 && counts[ranks[1]] == 2:
+		rank = hand.FullHouse
+	case counts[ranks[0]] == 3:
+		rank = hand.ThreeOfAKind
+	case counts[ranks[0]] == 2 && counts[ranks[1]] == 2:
+		rank = hand.TwoPair
+	case counts[ranks[0]] == 2:
+		rank = hand.OnePair
+	default:
+		rank = hand.HighCard
+	}
+
+	if countStraights && rank == hand.HighCard {
+		isStraight := ranks[0]-ranks[len(ranks)-1] == sizeHand-1
+		isFlush := true
+		for _, c := range h {
+			if c.Suit != h[0].Suit {
+				isFlush = false
+			}
+		}
+		switch {
+		case isStraight && isFlush:
+			rank = hand.StraightFlush
+		case isFlush:
+			rank = hand.Flush
+		case isStraight:
+			rank = hand.Straight
+		}
+	}
+	return append([]int{int(rank)}, ranks...)
+}
+
+func lowRankIndex(r card.RANK, aceLow bool) int {
+	if aceLow && r == card.Ace {
+		return -1
+	}
+	return card.RankIndexes[r]
+}
+
+func compareKeys(k1, k2 []int) int {
+	for i := range k1 {
+		if k1[i] < k2[i] {
+			return -1
+		} else if k1[i] > k2[i] {
+			return 1
+		}
+	}
+	return 0
+}
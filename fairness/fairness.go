@@ -0,0 +1,227 @@
+package fairness
+
+import (
+	"fmt"
+	"math"
+	"strings"
+
+	"github.com/aultimus/gosouth/card"
+	"github.com/aultimus/gosouth/deck"
+)
+
+// Options configure a fairness run, zero fields take their defaults
+type Options struct {
+	// Shuffles is the number of shuffles of each deck, default 1,000,000
+	Shuffles int
+	// DeckSize is the number of cards in the deck used for the position
+	// tests, default 52
+	DeckSize int
+	// PermutationSize is the number of cards in the deck used for the
+	// permutation test, every order of them is counted, default 5
+	PermutationSize int
+}
+
+// Result is the outcome of a chi-square test. A small PValue, the chance
+// of a statistic at least this large from a fair shuffle, is evidence of bias.
+type Result struct {
+	Name      string
+	Statistic float64
+	DF        int
+	PValue    float64
+}
+
+// Report holds the results of a fairness run
+type Report struct {
+	Shuffles int
+	Results  []Result
+}
+
+// Pass returns true if no test rejects a fair shuffle at significance
+// alpha, the alpha is divided between the tests
+func (r *Report) Pass(alpha float64) bool {
+	for _, res := range r.Results {
+		if res.PValue < alpha/float64(len(r.Results)) {
+			return false
+		}
+	}
+	return true
+}
+
+func (r *Report) String() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%d shuffles\n", r.Shuffles)
+	for _, res := range r.Results {
+		fmt.Fprintf(&b, "%-12s chi2 %12.2f  df %6d  p %.4f\n", res.Name, res.Statistic, res.DF, res.PValue)
+	}
+	return b.String()
+}
+
+// Run shuffles decks with s and tests the orders they come out in:
+//   - position, every card is equally likely in every position
+//   - first, the first card dealt is uniform
+//   - last, the last card dealt is uniform
+//   - permutation, every order of a small deck is equally likely
+func Run(s deck.Shuffler, o Options) (*Report, error) {
+	if o.Shuffles == 0 {
+		o.Shuffles = 1000000
+	}
+	if o.DeckSize == 0 {
+		o.DeckSize = 52
+	}
+	if o.PermutationSize == 0 {
+		o.PermutationSize = 5
+	}
+	if o.Shuffles < 0 || o.DeckSize < 2 || o.DeckSize > 52 || o.PermutationSize < 2 || o.PermutationSize > 8 {
+		return nil, fmt.Errorf("invalid options %+v", o)
+	}
+	r := &Report{Shuffles: o.Shuffles}
+	r.Results = append(r.Results, positions(s, o.Shuffles, o.DeckSize)...)
+	r.Results = append(r.Results, permutations(s, o.Shuffles, o.PermutationSize))
+	return r, nil
+}
+
+// index maps the cards of a fresh deck to their place in it
+func index(d deck.Deck) map[*card.Card]int {
+	m := make(map[*card.Card]int, len(d))
+	for i, c := range d {
+		m[c] = i
+	}
+	return m
+}
+
+// positions counts the position each card is shuffled to
+func positions(s deck.Shuffler, shuffles, n int) []Result {
+	orig := deck.New()[:n]
+	idx := index(orig)
+	counts := make([][]int, n)
+	for i := range counts {
+		counts[i] = make([]int, n)
+	}
+	d := make(deck.Deck, n)
+	for k := 0; k < shuffles; k++ {
+		copy(d, orig)
+		for pos, c := range s.Shuffle(d) {
+			counts[idx[c]][pos]++
+		}
+	}
+
+	expected := float64(shuffles) / float64(n)
+	var all, first, last float64
+	for c := range counts {
+		for pos, obs := range counts[c] {
+			x := sq(float64(obs)-expected) / expected
+			all += x
+			if pos == 0 {
+				first += x
+			}
+			if pos == n-1 {
+				last += x
+			}
+		}
+	}
+	return []Result{
+		result("position", all, (n-1)*(n-1)),
+		result("first", first, n-1),
+		result("last", last, n-1),
+	}
+}
+
+// permutations counts each order a small deck is shuffled into
+func permutations(s deck.Shuffler, shuffles, n int) Result {
+	orig := deck.New()[:n]
+	idx := index(orig)
+	numPerms := 1
+	for i := 2; i <= n; i++ {
+		numPerms *= i
+	}
+	counts := make([]int, numPerms)
+	d := make(deck.Deck, n)
+	perm := make([]int, n)
+	for k := 0; k < shuffles; k++ {
+		copy(d, orig)
+		for i, c := range s.Shuffle(d) {
+			perm[i] = idx[c]
+		}
+		counts[lehmer(perm)]++
+	}
+
+	expected := float64(shuffles) / float64(numPerms)
+	stat := 0.0
+	for _, obs := range counts {
+		stat += sq(float64(obs)-expected) / expected
+	}
+	return result("permutation", stat, numPerms-1)
+}
+
+// lehmer ranks a permutation of 0 to n-1 by its Lehmer code
+func lehmer(perm []int) int {
+	rank := 0
+	for i, p := range perm {
+		smaller := 0
+		for _, q := range perm[i+1:] {
+			if q < p {
+				smaller++
+			}
+		}
+		rank = rank*(len(perm)-i) + smaller
+	}
+	return rank
+}
+
+func sq(x float64) float64 {
+	return x * x
+}
+
+func result(name string, stat float64, df int) Result {
+	return Result{Name: name, Statistic: stat, DF: df, PValue: ChiSquareP(stat, df)}
+}
+
+// ChiSquareP returns the chance of a chi-square statistic of at least x
+// with df degrees of freedom, the regularized upper incomplete gamma
+// function Q(df/2, x/2)
+func ChiSquareP(x float64, df int) float64 {
+	if x <= 0 {
+		return 1
+	}
+	a, x := float64(df)/2, x/2
+	lg, _ := math.Lgamma(a)
+	prefix := math.Exp(a*math.Log(x) - x - lg)
+	const eps = 1e-15
+	if x < a+1 {
+		// series for the lower function P(a, x)
+		sum, term := 1/a, 1/a
+		for n := 1.0; n < 1e6; n++ {
+			term *= x / (a + n)
+			sum += term
+			if term < sum*eps {
+				break
+			}
+		}
+		return math.Max(0, 1-prefix*sum)
+	}
+	// Lentz's continued fraction for Q(a, x)
+	const tiny = 1e-300
+	b := x + 1 - a
+	c := 1 / tiny
+	d := 1 / b
+	h := d
+	for i := 1.0; i < 1e6; i++ {
+		an := -i * (i - a)
+		b += 2
+		d = an*d + b
+		if math.Abs(d) < tiny {
+			d = tiny
+		}
+		c = b + an/c
+		if math.Abs(c) < tiny {
+			c = tiny
+		}
+		d = 1 / d
+		delta := d * c
+		h *= delta
+		if math.Abs(delta-1) < eps {
+			break
+		}
+	}
+	return prefix * h
+}
@@ -0,0 +1,130 @@
+package fairness
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+
+	"github.com/aultimus/gosouth/deck"
+	"github.com/stretchr/testify/assert"
+)
+
+// shortLoop is the old knuthShuffle whose loop stopped
+// before the last two positions could be swapped
+type shortLoop struct {
+	rng *rand.Rand
+}
+
+func (s shortLoop) Shuffle(d deck.Deck) deck.Deck {
+	for i := 0; i < len(d)-2; i++ {
+		j := s.rng.Intn(len(d) - i)
+		d[i], d[i+j] = d[i+j], d[i]
+	}
+	return d
+}
+
+// naive swaps every card with any card, which favours some orders
+type naive struct {
+	rng *rand.Rand
+}
+
+func (s naive) Shuffle(d deck.Deck) deck.Deck {
+	for i := range d {
+		j := s.rng.Intn(len(d))
+		d[i], d[j] = d[j], d[i]
+	}
+	return d
+}
+
+// hands deals each shuffle as the next hand of a provably fair deal
+type hands struct {
+	f *deck.Fair
+}
+
+func (h hands) Shuffle(d deck.Deck) deck.Deck {
+	h.f.Nonce++
+	return h.f.Shuffle(d)
+}
+
+func TestChiSquareP(t *testing.T) {
+	a := assert.New(t)
+	a.Equal(1.0, ChiSquareP(0, 3))
+	a.InDelta(0.05, ChiSquareP(3.841459, 1), 1e-6)
+	a.InDelta(0.05, ChiSquareP(18.307038, 10), 1e-6)
+	a.InDelta(0.01, ChiSquareP(135.806723, 100), 1e-6)
+	// with two degrees of freedom the chance is exp(-x/2)
+	for _, x := range []float64{0.5, 2, 10, 40} {
+		a.InDelta(math.Exp(-x/2), ChiSquareP(x, 2), 1e-12)
+	}
+	// large degrees of freedom are near normal
+	a.InDelta(0.5, ChiSquareP(2600.33, 2601), 0.01)
+	a.True(ChiSquareP(3000, 2601) < 1e-6)
+}
+
+func TestLehmer(t *testing.T) {
+	a := assert.New(t)
+	a.Equal(0, lehmer([]int{0, 1, 2, 3}))
+	a.Equal(23, lehmer([]int{3, 2, 1, 0}))
+	a.Equal(1, lehmer([]int{0, 1, 3, 2}))
+	seen := make(map[int]bool)
+	perm := []int{0, 1, 2}
+	var walk func(k int)
+	walk = func(k int) {
+		if k == len(perm) {
+			seen[lehmer(perm)] = true
+			return
+		}
+		for i := k; i < len(perm); i++ {
+			perm[k], perm[i] = perm[i], perm[k]
+			walk(k + 1)
+			perm[k], perm[i] = perm[i], perm[k]
+		}
+	}
+	walk(0)
+	a.Equal(6, len(seen))
+}
+
+func TestRun(t *testing.T) {
+	a := assert.New(t)
+	o := Options{Shuffles: 20000}
+	r, err := Run(deck.NewSeeded(1), o)
+	a.NoError(err)
+	a.Equal(4, len(r.Results))
+	a.Equal(2601, r.Results[0].DF)
+	a.Equal(119, r.Results[3].DF)
+	a.True(r.Pass(0.001), r.String())
+
+	r, err = Run(shortLoop{rand.New(rand.NewSource(1))}, o)
+	a.NoError(err)
+	a.False(r.Pass(0.001))
+	a.True(r.Results[3].PValue < 1e-9)
+
+	r, err = Run(naive{rand.New(rand.NewSource(1))}, o)
+	a.NoError(err)
+	a.False(r.Pass(0.001))
+
+	r, err = Run(hands{&deck.Fair{ServerSeed: []byte("seed")}}, o)
+	a.NoError(err)
+	a.True(r.Pass(0.001), r.String())
+
+	_, err = Run(deck.Crypto, Options{DeckSize: 53})
+	a.Error(err)
+	_, err = Run(deck.Crypto, Options{PermutationSize: 1})
+	a.Error(err)
+}
+
+// Long running test
+func TestFairShuffles(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping TestFairShuffles as in short mode")
+	}
+	a := assert.New(t)
+	r, err := Run(deck.NewSeeded(2), Options{})
+	a.NoError(err)
+	a.Equal(1000000, r.Shuffles)
+	a.True(r.Pass(0.001), r.String())
+
+	r, err = Run(deck.Crypto, Options{Shuffles: 100000})
+	a.NoError(err)
+	a.True(r.Pass(0.001), r.String())
+}
@@ -0,0 +1,493 @@
+package game
+
+import (
+	"fmt"
+
+	"github.com/aultimus/gosouth/deck"
+	"github.com/aultimus/gosouth/hand"
+	"github.com/aultimus/gosouth/pot"
+)
+
+const (
+	numHoleCards = 2
+	numCommCards = 5
+	maxSeats     = 10
+)
+
+// STREET represents a betting round of a hand
+type STREET int
+
+const (
+	// Preflop constant
+	Preflop = STREET(iota)
+	// Flop constant
+	Flop = STREET(iota)
+	// Turn constant
+	Turn = STREET(iota)
+	// River constant
+	River = STREET(iota)
+	// Complete constant, the hand is over and the pot awarded
+	Complete = STREET(iota)
+)
+
+var streetNames = []string{"Preflop", "Flop", "Turn", "River", "Complete"}
+
+func (s STREET) String() string {
+	return streetNames[s]
+}
+
+// boardSize is the number of community cards out on each street
+var boardSize = []int{0, 3, 4, 5, 5}
+
+// ACTION represents the type of a player action
+type ACTION int
+
+const (
+	// Fold constant
+	Fold = ACTION(iota)
+	// Check constant
+	Check = ACTION(iota)
+	// Call constant
+	Call = ACTION(iota)
+	// Bet constant
+	Bet = ACTION(iota)
+	// Raise constant
+	Raise = ACTION(iota)
+)
+
+var actionNames = []string{"fold", "check", "call", "bet", "raise"}
+
+func (a ACTION) String() string {
+	return actionNames[a]
+}
+
+// Action is a player decision. For bets and raises Amount is the total
+// the seat has bet on the street once the action is made ("raise to").
+// For calls it is the number of chips added, calls may be made with
+// Amount zero and are filled in by Act.
+type Action struct {
+	Seat   int
+	Street STREET
+	Type   ACTION
+	Amount int
+	AllIn  bool
+}
+
+func (a Action) String() string {
+	switch a.Type {
+	case Bet, Raise:
+		return fmt.Sprintf("seat %d %s to %d", a.Seat, a.Type, a.Amount)
+	case Call:
+		return fmt.Sprintf("seat %d %s %d", a.Seat, a.Type, a.Amount)
+	}
+	return fmt.Sprintf("seat %d %s", a.Seat, a.Type)
+}
+
+// Legal is an action available to the seat to act, for bets and raises
+// Min and Max bound the "raise to" amount, for calls both are the
+// number of chips needed to call
+type Legal struct {
+	Type ACTION
+	Min  int
+	Max  int
+}
+
+// Config holds the stakes of a game, a nil Structure is no limit.
+// Ante is posted by every seat, BigBlindAnte is a single ante posted
+// for the table by the big blind after their blind.
+type Config struct {
+	SmallBlind   int
+	BigBlind     int
+	Ante         int
+	BigBlindAnte int
+	Structure    Structure
+}
+
+// Seat is a player's position at the table and their state in the hand
+type Seat struct {
+	Stack  int
+	Hole   hand.Hand
+	Bet    int // chips in front of the seat on the current street
+	Total  int // chips put in the pot this hand, antes included
+	Folded bool
+	AllIn  bool
+	// acted is true once the seat has acted since the last full raise
+	acted bool
+}
+
+// Game is the state of a single hand of Texas Hold'em. It is a
+// deterministic state machine, all randomness comes from the deck given
+// to New. Seats are indexed clockwise, the seat after the button posts
+// the small blind.
+type Game struct {
+	Config  Config
+	Seats   []*Seat
+	Button  int
+	Board   hand.Hand
+	Street  STREET
+	Actions []Action
+	// Pots holds the pots awarded once the hand is Complete
+	Pots []*pot.Pot
+	// Winnings holds the chips each seat collected once the hand is Complete
+	Winnings []int
+
+	dealer     *deck.Dealer
+	toAct      int
+	currentBet int
+	minRaise   int
+	numBets    int
+}
+
+// New starts a hand, posting antes and blinds and dealing hole cards from
+// deck d. stacks are the seat stacks before the hand.
+func New(cfg Config, stacks []int, button int, d deck.Deck) (*Game, error) {
+	if len(stacks) < 2 || len(stacks) > maxSeats {
+		return nil, fmt.Errorf("a game needs 2 to %d seats, not %d", maxSeats, len(stacks))
+	}
+	if button < 0 || button >= len(stacks) {
+		return nil, fmt.Errorf("button %d is not a seat", button)
+	}
+	if cfg.BigBlind <= 0 || cfg.SmallBlind < 0 || cfg.Ante < 0 || cfg.BigBlindAnte < 0 {
+		return nil, fmt.Errorf("invalid stakes %+v", cfg)
+	}
+	if numHoleCards*len(stacks)+numCommCards+3 > len(d) {
+		return nil, fmt.Errorf("deck of %d cards is too small for %d seats", len(d), len(stacks))
+	}
+	if cfg.Structure == nil {
+		cfg.Structure = NoLimit{}
+	}
+	g := &Game{
+		Config:   cfg,
+		Button:   button,
+		Winnings: make([]int, len(stacks)),
+		dealer:   deck.NewDealer(d),
+	}
+	for i, s := range stacks {
+		if s <= 0 {
+			return nil, fmt.Errorf("seat %d has no chips", i)
+		}
+		g.Seats = append(g.Seats, &Seat{Stack: s})
+	}
+
+	for i := range g.Seats {
+		g.post(i, cfg.Ante, false)
+	}
+	g.post(g.SmallBlindSeat(), cfg.SmallBlind, true)
+	g.post(g.BigBlindSeat(), cfg.BigBlind, true)
+	g.post(g.BigBlindSeat(), cfg.BigBlindAnte, false)
+	g.currentBet = cfg.BigBlind
+	g.minRaise = cfg.BigBlind
+	g.numBets = 1
+
+	// deal one card at a time starting with the small blind,
+	// the deck was checked to be large enough
+	dealt, _ := g.dealer.DealRound(len(g.Seats), numHoleCards)
+	for i, h := range dealt {
+		s := g.Seats[g.seatAfter(g.Button, i+1)]
+		s.Hole = hand.Hand(h)
+	}
+
+	g.toAct = g.BigBlindSeat()
+	g.advance()
+	return g, nil
+}
+
+// SmallBlindSeat returns the seat posting the small blind,
+// heads up the button posts the small blind
+func (g *Game) SmallBlindSeat() int {
+	if len(g.Seats) == 2 {
+		return g.Button
+	}
+	return g.seatAfter(g.Button, 1)
+}
+
+// BigBlindSeat returns the seat posting the big blind
+func (g *Game) BigBlindSeat() int {
+	return g.seatAfter(g.SmallBlindSeat(), 1)
+}
+
+func (g *Game) seatAfter(seat, n int) int {
+	return (seat + n) % len(g.Seats)
+}
+
+// post puts up to amount chips from a seat into the pot,
+// blinds count towards the seat's bet, antes do not
+func (g *Game) post(seat, amount int, live bool) {
+	s := g.Seats[seat]
+	if amount > s.Stack {
+		amount = s.Stack
+	}
+	s.Stack -= amount
+	s.Total += amount
+	if live {
+		s.Bet += amount
+	}
+	if s.Stack == 0 {
+		s.AllIn = true
+	}
+}
+
+// Done returns true once the hand is complete
+func (g *Game) Done() bool {
+	return g.Street == Complete
+}
+
+// ToAct returns the seat next to act, or -1 if the hand is complete
+func (g *Game) ToAct() int {
+	if g.Done() {
+		return -1
+	}
+	return g.toAct
+}
+
+// Pot returns the total chips put in by all seats, bets included
+func (g *Game) Pot() int {
+	total := 0
+	for _, s := range g.Seats {
+		total += s.Total
+	}
+	return total
+}
+
+// LegalActions returns the actions available to the seat to act
+func (g *Game) LegalActions() []Legal {
+	if g.Done() {
+		return nil
+	}
+	s := g.Seats[g.toAct]
+	toCall := g.currentBet - s.Bet
+	maxBet := s.Bet + s.Stack
+
+	var legal []Legal
+	if toCall > 0 {
+		legal = append(legal, Legal{Type: Fold})
+		call := min(toCall, s.Stack)
+		legal = append(legal, Legal{Type: Call, Min: call, Max: call})
+	} else {
+		legal = append(legal, Legal{Type: Check})
+	}
+	// raising is only possible if betting has been reopened to the seat
+	// and it has chips left after calling
+	if s.acted || maxBet <= g.currentBet {
+		return legal
+	}
+	lo, hi, ok := g.Config.Structure.RaiseRange(g.betState())
+	if !ok {
+		return legal
+	}
+	t := Raise
+	if g.currentBet == 0 {
+		t = Bet
+	}
+	return append(legal, Legal{
+		Type: t,
+		Min:  min(lo, maxBet),
+		Max:  min(hi, maxBet),
+	})
+}
+
+// betState describes the betting for the seat to act
+func (g *Game) betState() BetState {
+	s := g.Seats[g.toAct]
+	return BetState{
+		Street:     g.Street,
+		BigBlind:   g.Config.BigBlind,
+		CurrentBet: g.currentBet,
+		LastRaise:  g.minRaise,
+		NumBets:    g.numBets,
+		Pot:        g.Pot(),
+		Bet:        s.Bet,
+		Stack:      s.Stack,
+	}
+}
+
+// Act validates and applies an action by the seat to act
+func (g *Game) Act(a Action) error {
+	if g.Done() {
+		return fmt.Errorf("the hand is complete")
+	}
+	if a.Seat != g.toAct {
+		return fmt.Errorf("seat %d cannot act, it is seat %d's turn", a.Seat, g.toAct)
+	}
+	var legal *Legal
+	for _, l := range g.LegalActions() {
+		if l.Type == a.Type {
+			l := l
+			legal = &l
+		}
+	}
+	if legal == nil {
+		return fmt.Errorf("seat %d cannot %s", a.Seat, a.Type)
+	}
+
+	s := g.Seats[a.Seat]
+	a.Street = g.Street
+	switch a.Type {
+	case Fold:
+		s.Folded = true
+	case Call:
+		a.Amount = legal.Min
+		g.post(a.Seat, a.Amount, true)
+	case Bet, Raise:
+		if a.Amount < legal.Min || a.Amount > legal.Max {
+			return fmt.Errorf("seat %d cannot %s to %d, must be between %d and %d",
+				a.Seat, a.Type, a.Amount, legal.Min, legal.Max)
+		}
+		raise := a.Amount - g.currentBet
+		g.post(a.Seat, a.Amount-s.Bet, true)
+		g.currentBet = a.Amount
+		g.numBets++
+		// only a full raise reopens the betting
+		if raise >= g.minRaise {
+			g.minRaise = raise
+			for i, o := range g.Seats {
+				if i != a.Seat {
+					o.acted = false
+				}
+			}
+		}
+	}
+	s.acted = true
+	a.AllIn = s.AllIn && a.Type != Fold && a.Type != Check
+	g.Actions = append(g.Actions, a)
+	g.advance()
+	return nil
+}
+
+// inHand returns the seats that have not folded
+func (g *Game) inHand() []int {
+	var seats []int
+	for i, s := range g.Seats {
+		if !s.Folded {
+			seats = append(seats, i)
+		}
+	}
+	return seats
+}
+
+// needsAction returns true if a seat still has a decision on this street
+func (g *Game) needsAction(s *Seat) bool {
+	return !s.Folded && !s.AllIn && (!s.acted || s.Bet < g.currentBet)
+}
+
+// advance moves the turn to the next seat needing to act, moving on
+// to the next street or completing the hand as required
+func (g *Game) advance() {
+	if len(g.inHand()) == 1 {
+		g.returnUncalled()
+		g.complete()
+		return
+	}
+	for i := 1; i <= len(g.Seats); i++ {
+		seat := g.seatAfter(g.toAct, i)
+		if g.needsAction(g.Seats[seat]) {
+			// a lone seat with chips left has no one to bet against
+			// unless it is facing a bet
+			s := g.Seats[seat]
+			if g.numCanBet() == 1 && s.Bet >= g.currentBet {
+				break
+			}
+			g.toAct = seat
+			return
+		}
+	}
+	g.returnUncalled()
+	g.nextStreet()
+}
+
+// numCanBet returns the number of seats in the hand that are not all in
+func (g *Game) numCanBet() int {
+	n := 0
+	for _, s := range g.Seats {
+		if !s.Folded && !s.AllIn {
+			n++
+		}
+	}
+	return n
+}
+
+// returnUncalled gives back the part of the largest bet that nobody called
+func (g *Game) returnUncalled() {
+	top, second := -1, 0
+	for i, s := range g.Seats {
+		if top == -1 || s.Bet > g.Seats[top].Bet {
+			if top != -1 {
+				second = max(second, g.Seats[top].Bet)
+			}
+			top = i
+		} else {
+			second = max(second, s.Bet)
+		}
+	}
+	s := g.Seats[top]
+	if excess := s.Bet - second; excess > 0 {
+		s.Bet -= excess
+		s.Total -= excess
+		s.Stack += excess
+		s.AllIn = false
+	}
+}
+
+// nextStreet deals the next street, running the board out to showdown
+// if no more betting is possible
+func (g *Game) nextStreet() {
+	for _, s := range g.Seats {
+		s.Bet = 0
+		s.acted = false
+	}
+	g.currentBet = 0
+	g.minRaise = g.Config.BigBlind
+	g.numBets = 0
+	g.Street++
+	if g.Street == Complete {
+		g.complete()
+		return
+	}
+	// burn a card then deal the street
+	n := boardSize[g.Street] - len(g.Board)
+	_ = g.dealer.Burn()
+	cards, _ := g.dealer.Deal(n)
+	g.Board = append(g.Board, cards...)
+
+	if g.numCanBet() < 2 {
+		g.nextStreet()
+		return
+	}
+	// first to act after the flop is the first seat after the button
+	g.toAct = g.Button
+	g.advance()
+}
+
+// complete awards the pots and ends the hand
+func (g *Game) complete() {
+	g.Street = Complete
+	m := pot.New(len(g.Seats))
+	m.OddChip = pot.LeftOfButton(g.Button, len(g.Seats))
+	for i, s := range g.Seats {
+		m.Add(i, s.Total)
+		if s.Folded {
+			m.Fold(i)
+		}
+	}
+	pots, won, err := m.Award(func(eligible []int) []int {
+		var hands []hand.Hand
+		for _, seat := range eligible {
+			h := append(append(hand.Hand(nil), g.Seats[seat].Hole...), g.Board...)
+			hands = append(hands, h)
+		}
+		var winners []int
+		for _, w := range hand.Showdown(hands) {
+			winners = append(winners, eligible[w])
+		}
+		return winners
+	})
+	if err != nil {
+		// a seat is always left in the hand
+		panic(err)
+	}
+	g.Pots = pots
+	for i, w := range won {
+		g.Seats[i].Stack += w
+		g.Winnings[i] += w
+	}
+}
@@ -0,0 +1,223 @@
+package game
+
+import (
+	"testing"
+
+	"github.com/aultimus/gosouth/card"
+	"github.com/aultimus/gosouth/deck"
+	"github.com/aultimus/gosouth/hand"
+	"github.com/aultimus/gosouth/pot"
+	"github.com/stretchr/testify/assert"
+)
+
+var stakes = Config{SmallBlind: 1, BigBlind: 2}
+
+func mkHand(cards ...string) hand.Hand {
+	var h hand.Hand
+	for _, s := range cards {
+		h = append(h, card.New(card.RANK(s[:1]), card.SUIT(s[1:])))
+	}
+	return h
+}
+
+// stacked returns a deck that deals the given hole cards, listed by seat,
+// and board for a game with the button on seat 0
+func stacked(holes [][]string, board []string) deck.Deck {
+	var d deck.Deck
+	n := len(holes)
+	for c := 0; c < numHoleCards; c++ {
+		for i := 1; i <= n; i++ {
+			d = append(d, mkHand(holes[i%n][c])...)
+		}
+	}
+	b := mkHand(board...)
+	burns := mkHand("2H", "2D", "2S")
+	d = append(d, burns[0], b[0], b[1], b[2], burns[1], b[3], burns[2], b[4])
+	rest, err := deck.RemoveMultiple(deck.New(), d)
+	if err != nil {
+		panic(err)
+	}
+	return append(d, rest...)
+}
+
+func act(a *assert.Assertions, g *Game, seat int, t ACTION, amount int) {
+	a.NoError(g.Act(Action{Seat: seat, Type: t, Amount: amount}))
+}
+
+func TestBlinds(t *testing.T) {
+	a := assert.New(t)
+	g, err := New(stakes, []int{100, 100, 100}, 0, deck.New())
+	a.NoError(err)
+	a.Equal(1, g.SmallBlindSeat())
+	a.Equal(2, g.BigBlindSeat())
+	a.Equal(99, g.Seats[1].Stack)
+	a.Equal(98, g.Seats[2].Stack)
+	a.Equal(0, g.ToAct())
+	a.Equal(3, g.Pot())
+	a.Equal([]Legal{{Fold, 0, 0}, {Call, 2, 2}, {Raise, 4, 100}}, g.LegalActions())
+	for _, s := range g.Seats {
+		a.Equal(2, len(s.Hole))
+	}
+	// dealt from the small blind
+	a.Equal(deck.New()[0], g.Seats[1].Hole[0])
+
+	// heads up the button posts the small blind and acts first preflop
+	g, err = New(stakes, []int{100, 100}, 0, deck.New())
+	a.NoError(err)
+	a.Equal(0, g.SmallBlindSeat())
+	a.Equal(1, g.BigBlindSeat())
+	a.Equal(0, g.ToAct())
+	act(a, g, 0, Call, 0)
+	a.Equal([]Legal{{Check, 0, 0}, {Raise, 4, 100}}, g.LegalActions())
+	act(a, g, 1, Check, 0)
+	a.Equal(Flop, g.Street)
+	a.Equal(3, len(g.Board))
+	a.Equal(1, g.ToAct())
+	a.Equal([]Legal{{Check, 0, 0}, {Bet, 2, 98}}, g.LegalActions())
+
+	_, err = New(stakes, []int{100}, 0, deck.New())
+	a.Error(err)
+	_, err = New(stakes, []int{100, 0}, 0, deck.New())
+	a.Error(err)
+	_, err = New(stakes, []int{100, 100}, 2, deck.New())
+	a.Error(err)
+}
+
+func TestFoldToBigBlind(t *testing.T) {
+	a := assert.New(t)
+	g, err := New(stakes, []int{100, 100, 100}, 0, deck.New())
+	a.NoError(err)
+	a.Error(g.Act(Action{Seat: 1, Type: Fold}))
+	a.Error(g.Act(Action{Seat: 0, Type: Check}))
+	act(a, g, 0, Fold, 0)
+	act(a, g, 1, Fold, 0)
+	a.True(g.Done())
+	a.Equal(-1, g.ToAct())
+	a.Equal([]int{0, 0, 2}, g.Winnings)
+	a.Equal(101, g.Seats[2].Stack)
+	a.Equal(99, g.Seats[1].Stack)
+	a.Error(g.Act(Action{Seat: 2, Type: Check}))
+}
+
+func TestBigBlindAnte(t *testing.T) {
+	a := assert.New(t)
+	cfg := Config{SmallBlind: 1, BigBlind: 2, BigBlindAnte: 2}
+	g, err := New(cfg, []int{100, 100, 3}, 0, deck.New())
+	a.NoError(err)
+	// a short big blind covers their blind before the ante
+	a.Equal(0, g.Seats[2].Stack)
+	a.Equal(2, g.Seats[2].Bet)
+	a.Equal(3, g.Seats[2].Total)
+	a.Equal(4, g.Pot())
+	act(a, g, 0, Fold, 0)
+	act(a, g, 1, Fold, 0)
+	// the ante is dead, only the uncalled part of the blind is returned
+	a.Equal([]int{0, 0, 3}, g.Winnings)
+	a.Equal(4, g.Seats[2].Stack)
+
+	_, err = New(Config{BigBlind: 2, BigBlindAnte: -1}, []int{100, 100}, 0, deck.New())
+	a.Error(err)
+}
+
+func TestMinRaise(t *testing.T) {
+	a := assert.New(t)
+	g, err := New(stakes, []int{100, 100, 100}, 0, deck.New())
+	a.NoError(err)
+	a.Error(g.Act(Action{Seat: 0, Type: Raise, Amount: 3}))
+	a.Error(g.Act(Action{Seat: 0, Type: Raise, Amount: 101}))
+	act(a, g, 0, Raise, 6)
+	a.Equal([]Legal{{Fold, 0, 0}, {Call, 5, 5}, {Raise, 10, 100}}, g.LegalActions())
+	act(a, g, 1, Raise, 20)
+	a.Equal([]Legal{{Fold, 0, 0}, {Call, 18, 18}, {Raise, 34, 100}}, g.LegalActions())
+	act(a, g, 2, Call, 0)
+	act(a, g, 0, Call, 0)
+	a.Equal(Flop, g.Street)
+	a.Equal(60, g.Pot())
+	// first seat after the button opens the flop
+	a.Equal(1, g.ToAct())
+	act(a, g, 1, Check, 0)
+	act(a, g, 2, Bet, 10)
+	act(a, g, 0, Raise, 30)
+	act(a, g, 1, Fold, 0)
+	act(a, g, 2, Call, 0)
+	a.Equal(Turn, g.Street)
+	a.Equal(4, len(g.Board))
+	a.Equal(2, g.ToAct())
+
+	a.Equal(Action{Seat: 0, Street: Flop, Type: Raise, Amount: 30}, g.Actions[6])
+	a.Equal(Action{Seat: 2, Street: Flop, Type: Call, Amount: 20}, g.Actions[8])
+}
+
+func TestIncompleteRaise(t *testing.T) {
+	a := assert.New(t)
+	g, err := New(stakes, []int{100, 100, 30}, 0, deck.New())
+	a.NoError(err)
+	act(a, g, 0, Raise, 20)
+	act(a, g, 1, Call, 0)
+	// an all in for less than a full raise
+	act(a, g, 2, Raise, 30)
+	a.True(g.Actions[2].AllIn)
+	// does not reopen the betting to seats that have acted
+	a.Equal([]Legal{{Fold, 0, 0}, {Call, 10, 10}}, g.LegalActions())
+	a.Error(g.Act(Action{Seat: 0, Type: Raise, Amount: 60}))
+	act(a, g, 0, Call, 0)
+	act(a, g, 1, Call, 0)
+	a.Equal(Flop, g.Street)
+	a.Equal(90, g.Pot())
+}
+
+func TestSidePots(t *testing.T) {
+	a := assert.New(t)
+	d := stacked([][]string{{"AS", "AD"}, {"KS", "KD"}, {"QS", "QD"}},
+		[]string{"3C", "7D", "9H", "TS", "4D"})
+	g, err := New(stakes, []int{50, 100, 200}, 0, d)
+	a.NoError(err)
+	a.Equal(mkHand("AS", "AD"), g.Seats[0].Hole)
+	act(a, g, 0, Raise, 50)
+	act(a, g, 1, Raise, 100)
+	act(a, g, 2, Call, 0)
+	// everyone but seat 2 is all in, the board is run out
+	a.True(g.Done())
+	a.Equal(mkHand("3C", "7D", "9H", "TS", "4D"), g.Board)
+	a.Equal(2, len(g.Pots))
+	a.Equal(&pot.Pot{Amount: 150, Eligible: []int{0, 1, 2}, Winners: []int{0}, Shares: []int{150}}, g.Pots[0])
+	a.Equal(&pot.Pot{Amount: 100, Eligible: []int{1, 2}, Winners: []int{1}, Shares: []int{100}}, g.Pots[1])
+	a.Equal([]int{150, 100, 0}, g.Winnings)
+	a.Equal(150, g.Seats[0].Stack)
+	a.Equal(100, g.Seats[1].Stack)
+	a.Equal(100, g.Seats[2].Stack)
+}
+
+func TestUncalledBetReturned(t *testing.T) {
+	a := assert.New(t)
+	d := stacked([][]string{{"AS", "AD"}, {"KS", "KD"}, {"QS", "QD"}},
+		[]string{"3C", "7D", "9H", "TS", "4D"})
+	g, err := New(stakes, []int{200, 100, 100}, 0, d)
+	a.NoError(err)
+	act(a, g, 0, Raise, 200)
+	act(a, g, 1, Fold, 0)
+	act(a, g, 2, Call, 0)
+	a.True(g.Done())
+	a.Equal(1, len(g.Pots))
+	a.Equal(201, g.Pots[0].Amount)
+	a.Equal(301, g.Seats[0].Stack)
+	a.Equal(0, g.Seats[2].Stack)
+}
+
+func TestSplitPot(t *testing.T) {
+	a := assert.New(t)
+	d := stacked([][]string{{"AS", "KD"}, {"AD", "KS"}, {"2C", "3D"}},
+		[]string{"QC", "JD", "TH", "5S", "5D"})
+	g, err := New(Config{SmallBlind: 1, BigBlind: 2, Ante: 1}, []int{100, 100, 100}, 0, d)
+	a.NoError(err)
+	a.Equal(6, g.Pot())
+	act(a, g, 0, Call, 0)
+	act(a, g, 1, Call, 0)
+	act(a, g, 2, Check, 0)
+	for !g.Done() {
+		act(a, g, g.ToAct(), Check, 0)
+	}
+	a.Equal([]int{0, 1}, g.Pots[0].Winners)
+	// nine chips, the odd chip goes to the first winner after the button
+	a.Equal([]int{4, 5, 0}, g.Winnings)
+}
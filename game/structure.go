@@ -0,0 +1,112 @@
+package game
+
+// BetState is the information a Structure needs
+// to size a bet or raise by the seat to act
+type BetState struct {
+	Street   STREET
+	BigBlind int
+	// CurrentBet is the largest bet on the street
+	CurrentBet int
+	// LastRaise is the size of the last full bet or raise on the street
+	LastRaise int
+	// NumBets is the number of bets and raises on the street,
+	// preflop the big blind counts as the first bet
+	NumBets int
+	// Pot is all chips put in, bets on the current street included
+	Pot int
+	// Bet is the chips the seat to act has in front of it
+	Bet int
+	// Stack is the chips the seat to act has behind
+	Stack int
+}
+
+// Structure is a set of betting rules. RaiseRange returns the smallest and
+// largest amounts the seat to act may bet or raise to, ok is false if no
+// further raises are allowed. The game lets a seat go all in for less
+// than the minimum.
+type Structure interface {
+	Name() string
+	RaiseRange(s BetState) (min, max int, ok bool)
+}
+
+// NoLimit lets a seat bet any amount up to its whole stack, raising by at
+// least the size of the last bet or raise on the street
+type NoLimit struct{}
+
+// Name of the structure
+func (NoLimit) Name() string {
+	return "No Limit"
+}
+
+// RaiseRange for no limit
+func (NoLimit) RaiseRange(s BetState) (int, int, bool) {
+	return minRaiseTo(s), s.Bet + s.Stack, true
+}
+
+func minRaiseTo(s BetState) int {
+	return s.CurrentBet + max(s.LastRaise, s.BigBlind)
+}
+
+// PotLimit has the no limit minimum, a seat may raise
+// by at most the size of the pot after calling
+type PotLimit struct{}
+
+// Name of the structure
+func (PotLimit) Name() string {
+	return "Pot Limit"
+}
+
+// RaiseRange for pot limit
+func (PotLimit) RaiseRange(s BetState) (int, int, bool) {
+	toCall := s.CurrentBet - s.Bet
+	return minRaiseTo(s), s.CurrentBet + s.Pot + toCall, true
+}
+
+// FixedLimit bets and raises are of a fixed size, the small bet preflop
+// and on the flop and the big bet on the turn and river. Cap is the most
+// bets allowed on a street, the opening bet included, zero for no cap.
+type FixedLimit struct {
+	SmallBet int
+	BigBet   int
+	Cap      int
+}
+
+// Name of the structure
+func (FixedLimit) Name() string {
+	return "Fixed Limit"
+}
+
+// RaiseRange for fixed limit
+func (l FixedLimit) RaiseRange(s BetState) (int, int, bool) {
+	if l.Cap > 0 && s.NumBets >= l.Cap {
+		return 0, 0, false
+	}
+	size := l.SmallBet
+	if s.Street >= Turn {
+		size = l.BigBet
+	}
+	return s.CurrentBet + size, s.CurrentBet + size, true
+}
+
+// SpreadLimit bets may be any amount between Min and Max, a raise must be
+// at least the size of the last bet or raise on the street. Cap is the
+// most bets allowed on a street, zero for no cap.
+type SpreadLimit struct {
+	Min int
+	Max int
+	Cap int
+}
+
+// Name of the structure
+func (SpreadLimit) Name() string {
+	return "Spread Limit"
+}
+
+// RaiseRange for spread limit
+func (l SpreadLimit) RaiseRange(s BetState) (int, int, bool) {
+	if l.Cap > 0 && s.NumBets >= l.Cap {
+		return 0, 0, false
+	}
+	size := max(l.Min, s.LastRaise)
+	return s.CurrentBet + min(size, l.Max), s.CurrentBet + l.Max, true
+}
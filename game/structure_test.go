@@ -0,0 +1,83 @@
+package game
+
+import (
+	"testing"
+
+	"github.com/aultimus/gosouth/deck"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNoLimit(t *testing.T) {
+	a := assert.New(t)
+	lo, hi, ok := NoLimit{}.RaiseRange(BetState{BigBlind: 2, CurrentBet: 10, LastRaise: 8, Bet: 2, Stack: 98})
+	a.True(ok)
+	a.Equal(18, lo)
+	a.Equal(100, hi)
+}
+
+func TestPotLimit(t *testing.T) {
+	a := assert.New(t)
+	cfg := Config{SmallBlind: 1, BigBlind: 2, Structure: PotLimit{}}
+	g, err := New(cfg, []int{100, 100, 100}, 0, deck.New())
+	a.NoError(err)
+	// call 2 making the pot 5, then raise 5 more
+	a.Equal(Legal{Raise, 4, 7}, g.LegalActions()[2])
+	act(a, g, 0, Raise, 7)
+	// call 6 making the pot 16, then raise 16 more
+	a.Equal(Legal{Raise, 12, 23}, g.LegalActions()[2])
+	act(a, g, 1, Raise, 23)
+	act(a, g, 2, Fold, 0)
+	act(a, g, 0, Call, 0)
+	a.Equal(Flop, g.Street)
+	// first to act may bet the pot
+	a.Equal(Legal{Bet, 2, 48}, g.LegalActions()[1])
+	act(a, g, 1, Bet, 48)
+	// capped by the stack
+	a.Equal(Legal{Raise, 77, 77}, g.LegalActions()[2])
+}
+
+func TestFixedLimit(t *testing.T) {
+	a := assert.New(t)
+	cfg := Config{SmallBlind: 1, BigBlind: 2, Structure: FixedLimit{SmallBet: 2, BigBet: 4, Cap: 4}}
+	g, err := New(cfg, []int{100, 100, 100}, 0, deck.New())
+	a.NoError(err)
+	a.Equal(Legal{Raise, 4, 4}, g.LegalActions()[2])
+	a.Error(g.Act(Action{Seat: 0, Type: Raise, Amount: 6}))
+	act(a, g, 0, Raise, 4)
+	act(a, g, 1, Raise, 6)
+	act(a, g, 2, Raise, 8)
+	// capped at four bets, the big blind being the first
+	a.Equal([]Legal{{Fold, 0, 0}, {Call, 4, 4}}, g.LegalActions())
+	act(a, g, 0, Call, 0)
+	act(a, g, 1, Call, 0)
+	a.Equal(Flop, g.Street)
+	a.Equal(Legal{Bet, 2, 2}, g.LegalActions()[1])
+	act(a, g, 1, Check, 0)
+	act(a, g, 2, Check, 0)
+	act(a, g, 0, Check, 0)
+	a.Equal(Turn, g.Street)
+	// the big bet on the turn
+	a.Equal(Legal{Bet, 4, 4}, g.LegalActions()[1])
+	act(a, g, 1, Bet, 4)
+	a.Equal(Legal{Raise, 8, 8}, g.LegalActions()[2])
+}
+
+func TestSpreadLimit(t *testing.T) {
+	a := assert.New(t)
+	cfg := Config{SmallBlind: 1, BigBlind: 2, Structure: SpreadLimit{Min: 2, Max: 10}}
+	g, err := New(cfg, []int{100, 100, 100}, 0, deck.New())
+	a.NoError(err)
+	a.Equal(Legal{Raise, 4, 12}, g.LegalActions()[2])
+	act(a, g, 0, Call, 0)
+	act(a, g, 1, Call, 0)
+	act(a, g, 2, Check, 0)
+	a.Equal(Legal{Bet, 2, 10}, g.LegalActions()[1])
+	act(a, g, 1, Bet, 6)
+	// a raise must be at least the size of the bet
+	a.Equal(Legal{Raise, 12, 16}, g.LegalActions()[2])
+
+	lo, hi, ok := SpreadLimit{Min: 2, Max: 10, Cap: 3}.RaiseRange(BetState{NumBets: 3})
+	a.False(ok)
+	a.Equal(0, lo)
+	a.Equal(0, hi)
+}
@@ -1,8 +1,10 @@
 package hand
 
 import (
+	"errors"
 	"fmt"
 	"sort"
+	"strings"
 
 	"github.com/aultimus/gosouth/card"
 	"github.com/aultimus/gosouth/deck"
@@ -11,6 +13,13 @@ import (
 // Hand represents a collection of cards
 type Hand []*card.Card
 
+var (
+	// ErrHandSize is wrapped by errors for a hand too small or large to evaluate
+	ErrHandSize = errors.New("wrong number of cards")
+	// ErrHoleCards is wrapped by errors for a player holding the wrong number of hole cards
+	ErrHoleCards = errors.New("wrong number of hole cards")
+)
+
 var numHoleCards = 2
 var numCommCards = 5
 var sizeHand = 5
@@ -52,6 +61,8 @@ const (
 	StraightFlush = RANK(iota)
 	// RoyalFlush constant
 	RoyalFlush = RANK(iota)
+	// FiveOfAKind constant, only possible with wild cards
+	FiveOfAKind = RANK(iota)
 )
 
 // Value encapsulates a showdown hand
@@ -75,10 +86,20 @@ func tieBreak(v1, v2 *Value) OUTCOME {
 	if v1.Rank != v2.Rank {
 		panic(fmt.Errorf("%s & %s are not of same rank", v1, v2))
 	}
+	h1, h2 := v1.Hand, v2.Hand
+	switch v1.Rank {
+	case Straight, StraightFlush, RoyalFlush:
+		// straights are sorted ascending with the ace leading a wheel,
+		// so only the top card matters
+		h1, h2 = h1[len(h1)-1:], h2[len(h2)-1:]
+	case Flush:
+		// flushes are sorted ascending, compare from the top down
+		h1, h2 = descending(h1), descending(h2)
+	}
 	// assuming hand is sorted correctly by detection function
-	for i := range v1.Hand {
-		r1 := card.RankIndexes[v1.Hand[i].Rank]
-		r2 := card.RankIndexes[v2.Hand[i].Rank]
+	for i := range h1 {
+		r1 := card.RankIndexes[h1[i].Rank]
+		r2 := card.RankIndexes[h2[i].Rank]
 		if r1 > r2 {
 			return H1Win
 		} else if r2 > r1 {
@@ -88,6 +109,24 @@ func tieBreak(v1, v2 *Value) OUTCOME {
 	return Draw
 }
 
+// descending returns a copy of h sorted from highest to lowest rank
+func descending(h Hand) Hand {
+	c := append(Hand(nil), h...)
+	sort.Sort(sort.Reverse(c))
+	return c
+}
+
+// Compare compares two hand values, determining whether v1
+// wins, v2 wins or if they draw
+func Compare(v1, v2 *Value) OUTCOME {
+	if v1.Rank > v2.Rank {
+		return H1Win
+	} else if v2.Rank > v1.Rank {
+		return H2Win
+	}
+	return tieBreak(v1, v2)
+}
+
 func (v Value) String() string {
 	return fmt.Sprintf("rank:%d, hand:%s",
 		v.Rank, v.Hand)
@@ -98,24 +137,30 @@ func (h Hand) Swap(i, j int)      { h[i], h[j] = h[j], h[i] }
 func (h Hand) Less(i, j int) bool { return card.RankIndexes[h[i].Rank] < card.RankIndexes[h[j].Rank] }
 
 // FormHand given the hole cards and community cards returns the best
-// Value that can be formed
+// Value that can be formed. Five and six card hands are also accepted
+// so that draw games and partial boards can be evaluated.
 func FormHand(h Hand) (*Value, error) {
 	var v *Value
-	if len(h) != numHoleCards+numCommCards {
-		return v, fmt.Errorf("Argument to FormHand should be hand of %d cards, not %d cards",
-			numHoleCards+numCommCards, len(h))
+	if len(h) < sizeHand || len(h) > numHoleCards+numCommCards {
+		return v, fmt.Errorf("%w: argument to FormHand should be hand of %d to %d cards, not %d cards",
+			ErrHandSize, sizeHand, numHoleCards+numCommCards, len(h))
+	}
+	if _, err := h.Set(); err != nil {
+		return v, err
 	}
+	// the detection functions reorder their input, work on a copy
+	h = append(Hand(nil), h...)
 	// Straight
-	hasStraight, isFlush, formedHand := straight(h)
+	hasStraight, isFlush, straightHand := straight(h)
 	if hasStraight {
 		if isFlush {
 			// Straight flush
 			straightVal := StraightFlush
 			// Royal Flush
-			if formedHand[sizeHand-1].Rank == card.Ace {
+			if straightHand[sizeHand-1].Rank == card.Ace {
 				straightVal = RoyalFlush
 			}
-			return NewHandValue(straightVal, formedHand), nil
+			return NewHandValue(straightVal, straightHand), nil
 		}
 	}
 
@@ -139,7 +184,7 @@ func FormHand(h Hand) (*Value, error) {
 
 	// Straight
 	if hasStraight {
-		return NewHandValue(Straight, formedHand), nil
+		return NewHandValue(Straight, straightHand), nil
 	}
 
 	// Three of a kind
@@ -171,13 +216,22 @@ func Showdown(hands []Hand) []int {
 	wins := make([]int, len(hands))
 	mostWins := 0
 
-	for i, h1 := range hands {
-		for j, h2 := range hands {
+	// evaluate each hand once up front rather than once per comparison
+	values := make([]*Value, len(hands))
+	for i, h := range hands {
+		v, err := FormHand(h)
+		if err != nil {
+			panic(err)
+		}
+		values[i] = v
+	}
+
+	for i, v1 := range values {
+		for j, v2 := range values {
 			if i == j {
 				continue
 			}
-			o := showdown(h1, h2)
-			//fmt.Printf("%s vs %s, winner is %d\n", h1, h2, o)
+			o := Compare(v1, v2)
 			if o == H1Win {
 				wins[i]++
 			}
@@ -207,26 +261,6 @@ func findJointWinners(a []int, n int) []int {
 	return c
 }
 
-// showdown performs a binary showdown of two hands, determining whether h1
-// wins, h2 wins or if they draw
-func showdown(h1, h2 Hand) OUTCOME {
-	v1, err := FormHand(h1)
-	if err != nil {
-		panic(err)
-	}
-	v2, err := FormHand(h2)
-	if err != nil {
-		panic(err)
-	}
-
-	if v1.Rank > v2.Rank {
-		return H1Win
-	} else if v2.Rank > v1.Rank {
-		return H2Win
-	}
-	return tieBreak(v1, v2)
-}
-
 func numSuited(h Hand) (card.SUIT, int) {
 	var m = map[card.SUIT]int{
 		card.Clubs:    0,
@@ -297,21 +331,42 @@ func findStraight(h Hand) (bool, bool, Hand) {
 	return false, false, formedHand
 }
 
-// straight returns a bool, representing whether a straight exists
+// straight returns a bool, representing whether a straight exists,
+// a bool representing whether it is a straight flush
 // and if so, the highest value of a straight in the given hand
 func straight(h Hand) (bool, bool, Hand) {
+	// a straight flush may be lower than the highest straight,
+	// so look amongst the cards of the most populous suit first
+	s, count := numSuited(h)
+	if count >= sizeHand {
+		var suited Hand
+		for _, c := range h {
+			if c.Suit == s {
+				suited = append(suited, c)
+			}
+		}
+		if hasStraight, formedHand := straightAceLow(suited); hasStraight {
+			return true, true, formedHand
+		}
+	}
+	// remove duplicates of rank, preferring the most populous suit
+	h = rmDupRanks(rmDupsOfOtherSuits(h, s))
+	hasStraight, formedHand := straightAceLow(h)
+	return hasStraight, false, formedHand
+}
+
+// straightAceLow sorts h and returns the highest straight in it,
+// treating the ace as low if there is no other straight
+func straightAceLow(h Hand) (bool, Hand) {
 	var formedHand Hand
-	// remove duplicates of rank not of the most populous suit
-	s, _ := numSuited(h)
-	h = rmDupsOfOtherSuits(h, s)
 	if len(h) < sizeHand {
-		return false, false, formedHand
+		return false, formedHand
 	}
 	// sort cards into order and check for straight
 	sort.Sort(h)
-	hasStraight, isFlush, formedHand := findStraight(h)
+	hasStraight, _, formedHand := findStraight(h)
 	if hasStraight {
-		return hasStraight, isFlush, formedHand
+		return hasStraight, formedHand
 	}
 	// Make ace low if exists and check for wheel
 	lastI := len(h) - 1
@@ -319,11 +374,24 @@ func straight(h Hand) (bool, bool, Hand) {
 
 	// No Ace means no straight
 	if last.Rank != card.Ace {
-		return false, false, formedHand
+		return false, formedHand
 	}
-	h = append(h[:lastI])
-	h = append(Hand{last}, h...)
-	return findStraight(h)
+	h = append(Hand{last}, h[:lastI]...)
+	hasStraight, _, formedHand = findStraight(h)
+	return hasStraight, formedHand
+}
+
+// rmDupRanks removes all but the first card of each rank
+func rmDupRanks(h Hand) Hand {
+	seen := make(map[card.RANK]bool)
+	var cleaned Hand
+	for _, v := range h {
+		if !seen[v.Rank] {
+			cleaned = append(cleaned, v)
+			seen[v.Rank] = true
+		}
+	}
+	return cleaned
 }
 
 // flush returns true if a flush is present and
@@ -474,6 +542,46 @@ func popKicker(h Hand) (Hand, *card.Card) {
 	return append(h[:topInd], h[topInd+1:]...), topCard
 }
 
+// Set returns the set of cards in the hand, it returns an
+// error if the hand holds a joker or a card twice
+func (h Hand) Set() (card.Set, error) {
+	return card.NewSet(h...)
+}
+
+// Validate checks the cards of every hand are valid and that no card is
+// held twice, across the hands as well as within them. Jokers may repeat.
+// Errors wrap card.ErrInvalidCard or card.ErrDuplicateCard.
+func Validate(hands ...Hand) error {
+	var seen card.Set
+	for _, h := range hands {
+		for _, c := range h {
+			if err := card.Validate(c); err != nil {
+				return err
+			}
+			if c.IsJoker() {
+				continue
+			}
+			if seen.Contains(c) {
+				return fmt.Errorf("%w %s", card.ErrDuplicateCard, c)
+			}
+			// the card was validated so it has an index
+			i, _ := card.Index(c)
+			seen |= 1 << i
+		}
+	}
+	return nil
+}
+
+// ValidateHoles checks each hand holds n hole cards, errors wrap ErrHoleCards
+func ValidateHoles(n int, hands ...Hand) error {
+	for i, h := range hands {
+		if len(h) != n {
+			return fmt.Errorf("%w: hand %d holds %d cards, not %d", ErrHoleCards, i, len(h), n)
+		}
+	}
+	return nil
+}
+
 // Remove removes a card from the given hand
 func Remove(h Hand, c *card.Card) Hand {
 	d := deck.Deck(h)
@@ -485,6 +593,33 @@ func Remove(h Hand, c *card.Card) Hand {
 	return h
 }
 
+// Parse reads a hand of cards as written by card.Parse, either
+// separated by spaces or commas, "AS KD", or back to back, "AsKd"
+func Parse(s string) (Hand, error) {
+	var h Hand
+	for i := 0; i < len(s); {
+		if s[i] == ' ' || s[i] == ',' {
+			i++
+			continue
+		}
+		n := 2
+		switch {
+		case s[i] == 'X' || s[i] == 'x':
+			n = 1
+		case strings.HasPrefix(s[i:], "10"):
+			n = 3
+		}
+		n = min(n, len(s)-i)
+		c, err := card.Parse(s[i : i+n])
+		if err != nil {
+			return nil, err
+		}
+		h = append(h, c)
+		i += n
+	}
+	return h, nil
+}
+
 // ToHandType where a hand type is a string of format AKs AA 89o
 func ToHandType(h Hand) string {
 	// TODO
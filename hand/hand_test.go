@@ -497,3 +497,150 @@ func TestShowdown(t *testing.T) {
 	a.Equal([]int{0, 1}, Showdown(hands))
 
 }
+
+func TestFormHandStraights(t *testing.T) {
+	a := assert.New(t)
+
+	// a paired card in the middle of a straight
+	v, err := FormHand(mkHand("5C", "6D", "7H", "7S", "8C", "9D", "KH"))
+	a.NoError(err)
+	a.Equal(Straight, v.Rank)
+	a.Equal([]card.RANK{card.Five, card.Six, card.Seven, card.Eight, card.Nine}, ranksOf(v.Hand))
+
+	// a straight flush below a higher straight
+	v, err = FormHand(mkHand("4C", "5C", "6C", "7C", "8C", "9D", "TD"))
+	a.NoError(err)
+	a.Equal(StraightFlush, v.Rank)
+	a.Equal(card.Eight, v.Hand[sizeHand-1].Rank)
+
+	// the wheel is the lowest straight
+	wheel, err := FormHand(mkHand("AC", "2D", "3H", "4S", "5C", "9D", "JD"))
+	a.NoError(err)
+	six, err := FormHand(mkHand("6C", "2D", "3H", "4S", "5C", "9D", "JD"))
+	a.NoError(err)
+	a.Equal(H2Win, Compare(wheel, six))
+}
+
+func TestFlushTieBreak(t *testing.T) {
+	a := assert.New(t)
+	v1, err := FormHand(mkHand("KH", "QH", "8H", "4H", "2H", "3C", "5D"))
+	a.NoError(err)
+	v2, err := FormHand(mkHand("AS", "JS", "9S", "5S", "3S", "3C", "5D"))
+	a.NoError(err)
+	a.Equal(H2Win, Compare(v1, v2))
+}
+
+func TestFormHandSizes(t *testing.T) {
+	a := assert.New(t)
+	// five and six cards are evaluated for draw games and partial boards
+	v, err := FormHand(mkHand("AS", "AD", "KC", "KH", "2S"))
+	a.NoError(err)
+	a.Equal(TwoPair, v.Rank)
+	v, err = FormHand(mkHand("AS", "AD", "AC", "KH", "KS", "2S"))
+	a.NoError(err)
+	a.Equal(FullHouse, v.Rank)
+	v, err = FormHand(mkHand("AS", "AD", "AC", "AH", "KS", "2S", "3D"))
+	a.NoError(err)
+	a.Equal(FourOfAKind, v.Rank)
+
+	for _, h := range []Hand{
+		mkHand("AS", "AD", "KC", "KH"),
+		mkHand("AS", "AD", "KC", "KH", "2S", "3S", "4S", "5S"),
+	} {
+		_, err = FormHand(h)
+		a.ErrorIs(err, ErrHandSize)
+	}
+
+	// the caller's cards are left in their order
+	h := mkHand("2S", "AD", "KC", "KH", "AS")
+	_, err = FormHand(h)
+	a.NoError(err)
+	a.Equal(mkHand("2S", "AD", "KC", "KH", "AS"), h)
+}
+
+func TestStraightTopCard(t *testing.T) {
+	a := assert.New(t)
+	// straights are decided by their top card alone
+	broadway, err := FormHand(mkHand("AS", "KD", "QC", "JH", "TS"))
+	a.NoError(err)
+	kingHigh, err := FormHand(mkHand("9S", "KD", "QC", "JH", "TS"))
+	a.NoError(err)
+	a.Equal(H1Win, Compare(broadway, kingHigh))
+	a.Equal(H2Win, Compare(kingHigh, broadway))
+
+	// the ace plays low in a wheel so a six high straight beats it
+	wheel, err := FormHand(mkHand("AS", "2D", "3C", "4H", "5S"))
+	a.NoError(err)
+	sixHigh, err := FormHand(mkHand("6S", "2D", "3C", "4H", "5S"))
+	a.NoError(err)
+	a.Equal(H2Win, Compare(wheel, sixHigh))
+
+	// the same straight in other suits draws
+	other, err := FormHand(mkHand("AH", "2S", "3D", "4C", "5H"))
+	a.NoError(err)
+	a.Equal(Draw, Compare(wheel, other))
+}
+
+func TestFlushCardOrder(t *testing.T) {
+	a := assert.New(t)
+	// flushes are compared from the highest card down
+	aceKing, err := FormHand(mkHand("AH", "KH", "4H", "3H", "2H"))
+	a.NoError(err)
+	aceQueen, err := FormHand(mkHand("AS", "QS", "JS", "TS", "8S"))
+	a.NoError(err)
+	a.Equal(H1Win, Compare(aceKing, aceQueen))
+
+	// down to the lowest card when the rest match
+	low, err := FormHand(mkHand("AD", "KD", "9D", "7D", "2D"))
+	a.NoError(err)
+	high, err := FormHand(mkHand("AC", "KC", "9C", "7C", "3C"))
+	a.NoError(err)
+	a.Equal(H2Win, Compare(low, high))
+	same, err := FormHand(mkHand("AS", "KS", "9S", "7S", "2S"))
+	a.NoError(err)
+	a.Equal(Draw, Compare(low, same))
+}
+
+func TestCompareRanks(t *testing.T) {
+	a := assert.New(t)
+	flush, err := FormHand(mkHand("AH", "KH", "4H", "3H", "2H"))
+	a.NoError(err)
+	straight, err := FormHand(mkHand("AS", "KD", "QC", "JH", "TS"))
+	a.NoError(err)
+	a.Equal(H1Win, Compare(flush, straight))
+	a.Equal(H2Win, Compare(straight, flush))
+}
+
+func TestParse(t *testing.T) {
+	a := assert.New(t)
+	want := Hand{card.New(card.Ace, card.Spades), card.New(card.Ten, card.Diamonds), card.NewJoker()}
+	for _, s := range []string{"AS TD X", "AsTdx", "AS,10D,X", " as  td x "} {
+		h, err := Parse(s)
+		a.NoError(err, s)
+		a.Equal(want, h, s)
+	}
+	h, err := Parse("")
+	a.NoError(err)
+	a.Empty(h)
+	for _, s := range []string{"AsT", "AsKz", "A S"} {
+		_, err := Parse(s)
+		a.Error(err, s)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	a := assert.New(t)
+	h1, h2 := mkHand("AS", "KS"), mkHand("AH", "KH")
+	a.NoError(Validate(h1, h2, mkHand("X", "X")))
+	a.ErrorIs(Validate(h1, mkHand("AS", "2C")), card.ErrDuplicateCard)
+	a.ErrorIs(Validate(mkHand("QD", "QD")), card.ErrDuplicateCard)
+	a.ErrorIs(Validate(h1, Hand{&card.Card{Rank: card.Ace, Suit: card.SUIT("Z")}}), card.ErrInvalidCard)
+
+	a.NoError(ValidateHoles(2, h1, h2))
+	a.ErrorIs(ValidateHoles(2, h1, mkHand("AH")), ErrHoleCards)
+
+	_, err := FormHand(mkHand("AS", "KS"))
+	a.ErrorIs(err, ErrHandSize)
+	_, err = FormHand(mkHand("AS", "KS", "QS", "JS", "AS"))
+	a.ErrorIs(err, card.ErrDuplicateCard)
+}
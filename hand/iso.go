@@ -0,0 +1,115 @@
+package hand
+
+import "github.com/aultimus/gosouth/card"
+
+// suitPerms holds the 24 ways of relabelling the four suits
+var suitPerms = makeSuitPerms()
+
+func makeSuitPerms() [][card.NumSuit]int {
+	var perms [][card.NumSuit]int
+	var perm [card.NumSuit]int
+	used := make([]bool, card.NumSuit)
+	var permute func(i int)
+	permute = func(i int) {
+		if i == card.NumSuit {
+			perms = append(perms, perm)
+			return
+		}
+		for s := 0; s < card.NumSuit; s++ {
+			if !used[s] {
+				used[s] = true
+				perm[i] = s
+				permute(i + 1)
+				used[s] = false
+			}
+		}
+	}
+	permute(0)
+	return perms
+}
+
+// isoKey writes groups of cards, given as rank and suit indexes, with
+// their suits relabelled by perm, each group sorted, into b as one byte
+// per card with groups separated
+func isoKey(b []byte, ranks, suits, sizes []int, perm [card.NumSuit]int) []byte {
+	b = b[:0]
+	i := 0
+	for g, n := range sizes {
+		if g > 0 {
+			b = append(b, 0xff)
+		}
+		start := len(b)
+		for ; n > 0; n-- {
+			v := byte(ranks[i]*card.NumSuit + perm[suits[i]])
+			// insertion sort, groups are a handful of cards
+			j := len(b)
+			b = append(b, v)
+			for ; j > start && b[j-1] < v; j-- {
+				b[j] = b[j-1]
+			}
+			b[j] = v
+			i++
+		}
+	}
+	return b
+}
+
+// Canonical maps groups of cards, such as each player's hole cards then
+// the board, to the one representative of every situation equal to them
+// under a relabelling of the suits. The order of cards within a group does
+// not matter, so deal the flop, turn and river as separate groups if their
+// order does. It also returns the number of distinct situations the
+// representative stands for, from 1 to 24. Jokers are not supported.
+func Canonical(groups ...Hand) ([]Hand, int) {
+	key, n := canonicalKey(groups)
+	var canon []Hand
+	for _, h := range groups {
+		g := make(Hand, len(h))
+		for i := range g {
+			b := key[0]
+			key = key[1:]
+			g[i] = card.New(card.Ranks[int(b)/card.NumSuit], card.Suits[int(b)%card.NumSuit])
+		}
+		key = key[min(1, len(key)):]
+		canon = append(canon, g)
+	}
+	return canon, n
+}
+
+// CanonicalKey returns a key shared by every situation equal to the
+// groups under a relabelling of the suits, for caching results against
+func CanonicalKey(groups ...Hand) string {
+	key, _ := canonicalKey(groups)
+	return key
+}
+
+func canonicalKey(groups []Hand) (string, int) {
+	var ranks, suits, sizes []int
+	for _, h := range groups {
+		for _, c := range h {
+			ranks = append(ranks, card.RankIndexes[c.Rank])
+			suits = append(suits, card.SuitIndexes[c.Suit])
+		}
+		sizes = append(sizes, len(h))
+	}
+	size := len(ranks) + max(len(groups)-1, 0)
+	keys := make([]byte, 0, size*len(suitPerms))
+	best := 0
+	distinct := 0
+	for _, perm := range suitPerms {
+		k := isoKey(keys[len(keys):len(keys)], ranks, suits, sizes, perm)
+		seen := false
+		for d := 0; d < distinct && !seen; d++ {
+			seen = string(keys[d*size:(d+1)*size]) == string(k)
+		}
+		if seen {
+			continue
+		}
+		keys = keys[:len(keys)+size]
+		if string(k) < string(keys[best*size:(best+1)*size]) {
+			best = distinct
+		}
+		distinct++
+	}
+	return string(keys[best*size : (best+1)*size]), distinct
+}
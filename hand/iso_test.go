@@ -0,0 +1,59 @@
+package hand
+
+import (
+	"testing"
+
+	"github.com/aultimus/gosouth/deck"
+	"github.com/stretchr/testify/assert"
+)
+
+func mustParse(a *assert.Assertions, s string) Hand {
+	h, err := Parse(s)
+	a.NoError(err)
+	return h
+}
+
+func TestCanonical(t *testing.T) {
+	a := assert.New(t)
+	k1 := CanonicalKey(mustParse(a, "AS KS"), mustParse(a, "2H 7H 9C"))
+	k2 := CanonicalKey(mustParse(a, "KH AH"), mustParse(a, "9S 2S 7S"))
+	k3 := CanonicalKey(mustParse(a, "AS KH"), mustParse(a, "2H 7H 9C"))
+	a.NotEqual(k1, k3)
+	a.NotEqual(k1, k2)
+	a.Equal(k1, CanonicalKey(mustParse(a, "KH AH"), mustParse(a, "9D 2S 7S")))
+
+	canon, n := Canonical(mustParse(a, "AS KS"), mustParse(a, "2H 7H 9C"))
+	a.Equal(24, n)
+	a.Equal(2, len(canon[0]))
+	a.Equal(3, len(canon[1]))
+	a.Equal(k1, CanonicalKey(canon...))
+	a.Equal(canon[0][0].Suit, canon[0][1].Suit)
+	a.NotEqual(canon[0][0].Suit, canon[1][0].Suit)
+
+	// a rainbow of four suits stands for every relabelling
+	_, n = Canonical(mustParse(a, "AS AH AD AC"))
+	a.Equal(1, n)
+	_, n = Canonical(mustParse(a, "AS KS"))
+	a.Equal(4, n)
+}
+
+func TestCanonicalCounts(t *testing.T) {
+	a := assert.New(t)
+	for _, tc := range []struct{ k, classes, total int }{
+		{2, 169, 1326},
+		{3, 1755, 22100},
+	} {
+		weights := make(map[string]int)
+		combs := deck.NewCombinations(deck.New(), tc.k)
+		for combs.Next() {
+			key, n := canonicalKey([]Hand{Hand(combs.Comb())})
+			weights[key] = n
+		}
+		total := 0
+		for _, n := range weights {
+			total += n
+		}
+		a.Equal(tc.classes, len(weights))
+		a.Equal(tc.total, total)
+	}
+}
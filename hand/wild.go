@@ -0,0 +1,281 @@
+package hand
+
+import (
+	"fmt"
+
+	"github.com/aultimus/gosouth/card"
+)
+
+// Wilds describes which cards play as wild cards.
+// Jokers are always wild.
+type Wilds struct {
+	Ranks []card.RANK
+}
+
+// JokersWild has only the jokers wild
+var JokersWild = Wilds{}
+
+// DeucesWild has every two, and any joker, wild
+var DeucesWild = Wilds{Ranks: []card.RANK{card.Two}}
+
+// IsWild returns true if c plays as a wild card
+func (w Wilds) IsWild(c *card.Card) bool {
+	if c.IsJoker() {
+		return true
+	}
+	for _, r := range w.Ranks {
+		if c.Rank == r {
+			return true
+		}
+	}
+	return false
+}
+
+// FormHandWild given the hole cards and community cards returns the best
+// Value that can be formed, with each wild card standing in for any card,
+// even one already in the hand. Wild cards in the returned Value are
+// replaced by the cards they stand in for. Errors wrap card.ErrInvalidCard
+// or card.ErrDuplicateCard for an invalid or repeated card other than a joker.
+func FormHandWild(h Hand, w Wilds) (*Value, error) {
+	var v *Value
+	if len(h) < sizeHand || len(h) > numHoleCards+numCommCards {
+		return v, fmt.Errorf("%w: argument to FormHandWild should be hand of %d to %d cards, not %d cards",
+			ErrHandSize, sizeHand, numHoleCards+numCommCards, len(h))
+	}
+	// wild cards stand in for duplicates, the cards dealt may not be any
+	if err := Validate(h); err != nil {
+		return v, err
+	}
+	wh := wildHand{}
+	for _, c := range h {
+		if w.IsWild(c) {
+			wh.numWild++
+			continue
+		}
+		i := card.RankIndexes[c.Rank]
+		wh.byRank[i] = append(wh.byRank[i], c)
+	}
+	if wh.numWild == 0 {
+		return FormHand(h)
+	}
+	return wh.form(), nil
+}
+
+// wildHand holds the natural cards of a hand grouped by rank index
+// and the number of wild cards available to substitute
+type wildHand struct {
+	byRank  [card.NumRanks]Hand
+	numWild int
+}
+
+// form works down the hand rankings returning the first that the
+// wild cards can make. With at least one wild card two pair and high
+// card can always be improved upon.
+func (wh *wildHand) form() *Value {
+	if f, ok := wh.fiveOfAKind(); ok {
+		return NewHandValue(FiveOfAKind, f)
+	}
+	if f, ok := wh.straightFlush(); ok {
+		if f[sizeHand-1].Rank == card.Ace {
+			return NewHandValue(RoyalFlush, f)
+		}
+		return NewHandValue(StraightFlush, f)
+	}
+	if f, ok := wh.xOfAKind(4); ok {
+		return NewHandValue(FourOfAKind, f)
+	}
+	if f, ok := wh.fullHouse(); ok {
+		return NewHandValue(FullHouse, f)
+	}
+	if f, ok := wh.flush(); ok {
+		return NewHandValue(Flush, f)
+	}
+	if f, ok := wh.straight(); ok {
+		return NewHandValue(Straight, f)
+	}
+	if f, ok := wh.xOfAKind(3); ok {
+		return NewHandValue(ThreeOfAKind, f)
+	}
+	f, _ := wh.xOfAKind(2)
+	return NewHandValue(OnePair, f)
+}
+
+// take returns up to n natural cards of rank index r, topped up
+// with wild cards standing in for that rank. ok is false if there
+// are not enough wild cards.
+func (wh *wildHand) take(r, n int, wilds *int) (Hand, bool) {
+	f := append(Hand(nil), wh.byRank[r]...)
+	if len(f) > n {
+		f = f[:n]
+	}
+	for len(f) < n {
+		if *wilds == 0 {
+			return nil, false
+		}
+		*wilds--
+		f = append(f, card.New(card.Ranks[r], card.Spades))
+	}
+	return f, true
+}
+
+func (wh *wildHand) fiveOfAKind() (Hand, bool) {
+	for r := card.NumRanks - 1; r >= 0; r-- {
+		wilds := wh.numWild
+		if f, ok := wh.take(r, sizeHand, &wilds); ok {
+			return f, true
+		}
+	}
+	return nil, false
+}
+
+// xOfAKind returns the highest x of a kind with the
+// best kickers from the remaining natural cards
+func (wh *wildHand) xOfAKind(x int) (Hand, bool) {
+	for r := card.NumRanks - 1; r >= 0; r-- {
+		wilds := wh.numWild
+		if f, ok := wh.take(r, x, &wilds); ok {
+			return append(f, wh.kickers(r, sizeHand-x)...), true
+		}
+	}
+	return nil, false
+}
+
+// kickers returns the n highest natural cards not of rank index exclude,
+// any shortfall is made up of the highest ranks not already used
+func (wh *wildHand) kickers(exclude, n int) Hand {
+	var k Hand
+	for r := card.NumRanks - 1; r >= 0 && len(k) < n; r-- {
+		if r == exclude {
+			continue
+		}
+		for _, c := range wh.byRank[r] {
+			if len(k) < n {
+				k = append(k, c)
+			}
+		}
+	}
+	for r := card.NumRanks - 1; r >= 0 && len(k) < n; r-- {
+		if r != exclude && len(wh.byRank[r]) == 0 {
+			k = append(k, card.New(card.Ranks[r], card.Spades))
+		}
+	}
+	return k
+}
+
+func (wh *wildHand) fullHouse() (Hand, bool) {
+	for r1 := card.NumRanks - 1; r1 >= 0; r1-- {
+		for r2 := card.NumRanks - 1; r2 >= 0; r2-- {
+			if r1 == r2 {
+				continue
+			}
+			wilds := wh.numWild
+			s, ok := wh.take(r1, 3, &wilds)
+			if !ok {
+				break
+			}
+			if t, ok := wh.take(r2, 2, &wilds); ok {
+				return append(s, t...), true
+			}
+		}
+	}
+	return nil, false
+}
+
+// straightWindow returns the rank indexes of the straight topped by
+// rank index top in ascending order, the ace leads a wheel
+func straightWindow(top int) []int {
+	var w []int
+	for r := top - sizeHand + 1; r <= top; r++ {
+		if r < 0 {
+			w = append(w, r+card.NumRanks)
+		} else {
+			w = append(w, r)
+		}
+	}
+	return w
+}
+
+// straightFlush returns the highest straight flush, sorted ascending
+func (wh *wildHand) straightFlush() (Hand, bool) {
+	for top := card.NumRanks - 1; top >= sizeHand-2; top-- {
+		for _, s := range card.Suits {
+			wilds := wh.numWild
+			var f Hand
+			for _, r := range straightWindow(top) {
+				c := wh.suited(r, s)
+				if c == nil {
+					if wilds == 0 {
+						break
+					}
+					wilds--
+					c = card.New(card.Ranks[r], s)
+				}
+				f = append(f, c)
+			}
+			if len(f) == sizeHand {
+				return f, true
+			}
+		}
+	}
+	return nil, false
+}
+
+// straight returns the highest straight, sorted ascending
+func (wh *wildHand) straight() (Hand, bool) {
+	for top := card.NumRanks - 1; top >= sizeHand-2; top-- {
+		wilds := wh.numWild
+		var f Hand
+		for _, r := range straightWindow(top) {
+			c, ok := wh.take(r, 1, &wilds)
+			if !ok {
+				break
+			}
+			f = append(f, c...)
+		}
+		if len(f) == sizeHand {
+			return f, true
+		}
+	}
+	return nil, false
+}
+
+// suited returns the natural card of rank index r and suit s if held
+func (wh *wildHand) suited(r int, s card.SUIT) *card.Card {
+	for _, c := range wh.byRank[r] {
+		if c.Suit == s {
+			return c
+		}
+	}
+	return nil
+}
+
+// flush returns the best flush, sorted ascending, wild cards
+// stand in for the highest cards of the suit not already held
+func (wh *wildHand) flush() (Hand, bool) {
+	var best Hand
+	for _, s := range card.Suits {
+		wilds := wh.numWild
+		var f Hand
+		for r := card.NumRanks - 1; r >= 0 && len(f) < sizeHand; r-- {
+			c := wh.suited(r, s)
+			if c == nil && wilds > 0 {
+				wilds--
+				c = card.New(card.Ranks[r], s)
+			}
+			if c != nil {
+				f = append(f, c)
+			}
+		}
+		if len(f) < sizeHand {
+			continue
+		}
+		// f is sorted descending, flushes are formed ascending
+		for i, j := 0, len(f)-1; i < j; i, j = i+1, j-1 {
+			f[i], f[j] = f[j], f[i]
+		}
+		if best == nil || tieBreak(NewHandValue(Flush, f), NewHandValue(Flush, best)) == H1Win {
+			best = f
+		}
+	}
+	return best, best != nil
+}
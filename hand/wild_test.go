@@ -0,0 +1,121 @@
+package hand
+
+import (
+	"testing"
+
+	"github.com/aultimus/gosouth/card"
+	"github.com/stretchr/testify/assert"
+)
+
+func mkHand(cards ...string) Hand {
+	var h Hand
+	for _, s := range cards {
+		if s == "X" {
+			h = append(h, card.NewJoker())
+			continue
+		}
+		h = append(h, card.New(card.RANK(s[:1]), card.SUIT(s[1:])))
+	}
+	return h
+}
+
+func ranksOf(h Hand) []card.RANK {
+	var r []card.RANK
+	for _, c := range h {
+		r = append(r, c.Rank)
+	}
+	return r
+}
+
+func TestIsWild(t *testing.T) {
+	a := assert.New(t)
+	a.True(JokersWild.IsWild(card.NewJoker()))
+	a.False(JokersWild.IsWild(card.New(card.Two, card.Clubs)))
+	a.True(DeucesWild.IsWild(card.New(card.Two, card.Clubs)))
+	a.True(DeucesWild.IsWild(card.NewJoker()))
+	a.False(DeucesWild.IsWild(card.New(card.Three, card.Clubs)))
+}
+
+func TestFormHandWild(t *testing.T) {
+	a := assert.New(t)
+	cases := []struct {
+		h     Hand
+		w     Wilds
+		rank  RANK
+		ranks []card.RANK
+	}{
+		{mkHand("AS", "AH", "AD", "AC", "X"), JokersWild, FiveOfAKind,
+			[]card.RANK{card.Ace, card.Ace, card.Ace, card.Ace, card.Ace}},
+		{mkHand("2S", "2H", "2D", "2C", "X"), JokersWild, FiveOfAKind,
+			[]card.RANK{card.Two, card.Two, card.Two, card.Two, card.Two}},
+		{mkHand("2S", "2H", "2D", "2C", "X"), DeucesWild, FiveOfAKind,
+			[]card.RANK{card.Ace, card.Ace, card.Ace, card.Ace, card.Ace}},
+		{mkHand("7S", "7H", "7D", "2C", "3S"), DeucesWild, FourOfAKind,
+			[]card.RANK{card.Seven, card.Seven, card.Seven, card.Seven, card.Three}},
+		{mkHand("TS", "JS", "QS", "KS", "2C"), DeucesWild, RoyalFlush,
+			[]card.RANK{card.Ten, card.Jack, card.Queen, card.King, card.Ace}},
+		{mkHand("AH", "3H", "4H", "5H", "X", "KD", "QS"), JokersWild, StraightFlush,
+			[]card.RANK{card.Ace, card.Two, card.Three, card.Four, card.Five}},
+		{mkHand("KS", "KH", "QD", "QC", "X"), JokersWild, FullHouse,
+			[]card.RANK{card.King, card.King, card.King, card.Queen, card.Queen}},
+		{mkHand("9H", "7H", "5H", "3H", "X", "KC", "KD"), JokersWild, Flush,
+			[]card.RANK{card.Three, card.Five, card.Seven, card.Nine, card.Ace}},
+		{mkHand("AH", "9H", "5H", "3H", "X", "KC", "JD"), JokersWild, Flush,
+			[]card.RANK{card.Three, card.Five, card.Nine, card.King, card.Ace}},
+		{mkHand("9C", "8D", "6H", "5S", "X"), JokersWild, Straight,
+			[]card.RANK{card.Five, card.Six, card.Seven, card.Eight, card.Nine}},
+		{mkHand("QC", "QD", "6H", "4S", "X", "8C", "9S"), JokersWild, ThreeOfAKind,
+			[]card.RANK{card.Queen, card.Queen, card.Queen, card.Nine, card.Eight}},
+		{mkHand("KC", "JD", "6H", "4S", "X"), JokersWild, OnePair,
+			[]card.RANK{card.King, card.King, card.Jack, card.Six, card.Four}},
+		// without wild cards the natural evaluator is used
+		{mkHand("KC", "JD", "6H", "4S", "8D"), JokersWild, HighCard,
+			[]card.RANK{card.King, card.Jack, card.Eight, card.Six, card.Four}},
+	}
+	for _, c := range cases {
+		v, err := FormHandWild(c.h, c.w)
+		a.NoError(err)
+		a.Equal(c.rank, v.Rank, "%s", c.h)
+		a.Equal(c.ranks, ranksOf(v.Hand), "%s", c.h)
+	}
+
+	_, err := FormHandWild(mkHand("X", "X", "X", "X"), JokersWild)
+	a.Error(err)
+}
+
+func TestWildTieBreak(t *testing.T) {
+	a := assert.New(t)
+	form := func(w Wilds, cards ...string) *Value {
+		v, err := FormHandWild(mkHand(cards...), w)
+		a.NoError(err)
+		return v
+	}
+	// five of a kind beats a natural royal flush
+	a.Equal(H1Win, Compare(form(DeucesWild, "2S", "9H", "9D", "9C", "9S"),
+		form(DeucesWild, "TS", "JS", "QS", "KS", "AS")))
+	// a wild card hand ties the natural hand it stands in for
+	a.Equal(Draw, Compare(form(JokersWild, "9H", "8D", "7C", "6S", "X"),
+		form(JokersWild, "9H", "8D", "7C", "6S", "TD")))
+	// the joker completes the higher straight
+	a.Equal(H1Win, Compare(form(JokersWild, "9H", "8D", "7C", "6S", "X"),
+		form(JokersWild, "9H", "8D", "7C", "6S", "5D")))
+	// the wild card plays as the highest missing card of the flush
+	a.Equal(H1Win, Compare(form(JokersWild, "9H", "7H", "5H", "3H", "X"),
+		form(JokersWild, "KS", "QS", "JS", "8S", "6S")))
+}
+
+func TestFormHandWildInvalid(t *testing.T) {
+	a := assert.New(t)
+	// a repeated card is an error even with a wild card to hide it
+	_, err := FormHandWild(mkHand("AS", "AS", "X", "KD", "7C"), JokersWild)
+	a.ErrorIs(err, card.ErrDuplicateCard)
+	_, err = FormHandWild(mkHand("AS", "KS", "2D", "KD", "KD"), DeucesWild)
+	a.ErrorIs(err, card.ErrDuplicateCard)
+	_, err = FormHandWild(mkHand("AS", "KS", "X", "KD", "1Z"), JokersWild)
+	a.ErrorIs(err, card.ErrInvalidCard)
+
+	// any number of jokers may be held
+	v, err := FormHandWild(mkHand("AS", "AD", "X", "X", "7C"), JokersWild)
+	a.NoError(err)
+	a.Equal(FourOfAKind, v.Rank)
+}
@@ -0,0 +1,252 @@
+package headsup
+
+import (
+	"bufio"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"iter"
+	"runtime"
+	"sort"
+	"sync"
+
+	"github.com/aultimus/gosouth/deck"
+	"github.com/aultimus/gosouth/hand"
+)
+
+// equityDBMagic starts every equity database file
+const equityDBMagic = "GSEQ"
+
+// equityDBVersion is bumped whenever the file layout changes
+const equityDBVersion = 1
+
+// equityScale stores win percentages to two decimal places
+const equityScale = 100
+
+// Spot is a situation to precompute, one hand is played against a
+// random hand and more against one another
+type Spot struct {
+	Board hand.Hand
+	Hands []hand.Hand
+}
+
+func (s Spot) key() string {
+	return hand.CanonicalKey(append([]hand.Hand{s.Board}, s.Hands...)...)
+}
+
+// CanonicalBoards returns one board of size cards for
+// each set of boards equal under a relabelling of the suits
+func CanonicalBoards(size int) []hand.Hand {
+	seen := make(map[string]bool)
+	var boards []hand.Hand
+	combs := deck.NewCombinations(deck.New(), size)
+	for combs.Next() {
+		b := hand.Hand(combs.Comb())
+		if k := hand.CanonicalKey(b); !seen[k] {
+			seen[k] = true
+			boards = append(boards, append(hand.Hand(nil), b...))
+		}
+	}
+	return boards
+}
+
+// Spots returns every distinct spot on a board, each hand against a
+// random hand if vsRandom is set and otherwise each pair of hands
+func Spots(board hand.Hand, vsRandom bool) iter.Seq[Spot] {
+	return func(yield func(Spot) bool) {
+		d, err := deck.RemoveMultiple(deck.New(), board)
+		if err != nil {
+			return
+		}
+		seen := make(map[string]bool)
+		try := func(s Spot) bool {
+			k := s.key()
+			if seen[k] {
+				return true
+			}
+			seen[k] = true
+			return yield(s)
+		}
+		holes := deck.NewCombinations(d, numHoleCards)
+		for holes.Next() {
+			h1 := append(hand.Hand(nil), holes.Comb()...)
+			if vsRandom {
+				if !try(Spot{Board: board, Hands: []hand.Hand{h1}}) {
+					return
+				}
+				continue
+			}
+			hole, _ := h1.Set()
+			rest := deck.Without(d, hole)
+			others := deck.NewCombinations(rest, numHoleCards)
+			for others.Next() {
+				h2 := append(hand.Hand(nil), others.Comb()...)
+				if !try(Spot{Board: board, Hands: []hand.Hand{h1, h2}}) {
+					return
+				}
+			}
+		}
+	}
+}
+
+// BoardSpots returns the distinct spots on each board in turn, as Spots
+// does for one. Spots on boards that are not equal under a relabelling of
+// the suits never share a key, so CanonicalBoards can be passed as is.
+func BoardSpots(boards []hand.Hand, vsRandom bool) iter.Seq[Spot] {
+	return func(yield func(Spot) bool) {
+		for _, b := range boards {
+			for s := range Spots(b, vsRandom) {
+				if !yield(s) {
+					return
+				}
+			}
+		}
+	}
+}
+
+// EquityDB holds precomputed results keyed by canonical spot,
+// build one with GenerateEquityDB or read one with ReadEquityDB
+type EquityDB struct {
+	results map[string][]uint16
+}
+
+// GenerateEquityDB computes the result of every spot on all CPUs.
+// Precomputing every flop takes a long time, generate the
+// boards needed or run cmd/equitydb once and keep the file.
+func GenerateEquityDB(spots iter.Seq[Spot]) (*EquityDB, error) {
+	db := &EquityDB{results: make(map[string][]uint16)}
+	var (
+		mu    sync.Mutex
+		wg    sync.WaitGroup
+		first error
+	)
+	todo := make(chan Spot)
+	for i := 0; i < runtime.NumCPU(); i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for s := range todo {
+				r, err := ProbBoard(s.Board, nil, s.Hands...)
+				mu.Lock()
+				if err != nil && first == nil {
+					first = fmt.Errorf("%s %s: %w", s.Board, s.Hands, err)
+				}
+				if err == nil {
+					db.results[s.key()] = encodeWin(r.Win)
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+	for s := range spots {
+		todo <- s
+	}
+	close(todo)
+	wg.Wait()
+	if first != nil {
+		return nil, first
+	}
+	return db, nil
+}
+
+func encodeWin(win []float64) []uint16 {
+	out := make([]uint16, len(win))
+	for i, w := range win {
+		out[i] = uint16(w*equityScale + 0.5)
+	}
+	return out
+}
+
+// Len returns the number of spots held
+func (db *EquityDB) Len() int {
+	return len(db.results)
+}
+
+// Lookup returns the result of a spot, or false if it was not precomputed
+// or holds an invalid or duplicate card.
+// The spot may be given in any suits, as with the Spot it was computed as.
+func (db *EquityDB) Lookup(board hand.Hand, hands ...hand.Hand) (*Result, bool) {
+	if hand.Validate(append([]hand.Hand{board}, hands...)...) != nil {
+		return nil, false
+	}
+	win, ok := db.results[Spot{Board: board, Hands: hands}.key()]
+	if !ok {
+		return nil, false
+	}
+	r := NewResult(len(win))
+	for i, w := range win {
+		r.Win[i] = float64(w) / equityScale
+	}
+	return r, true
+}
+
+// WriteEquityDB writes db in a compact binary form: a magic string and
+// version, the number of spots, then each spot's canonical key and win
+// percentages in hundredths, sorted by key
+func WriteEquityDB(w io.Writer, db *EquityDB) error {
+	keys := make([]string, 0, len(db.results))
+	for k := range db.results {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	bw := bufio.NewWriter(w)
+	bw.WriteString(equityDBMagic)
+	bw.WriteByte(equityDBVersion)
+	binary.Write(bw, binary.BigEndian, uint32(len(keys)))
+	for _, k := range keys {
+		win := db.results[k]
+		bw.WriteByte(byte(len(k)))
+		bw.WriteString(k)
+		bw.WriteByte(byte(len(win)))
+		binary.Write(bw, binary.BigEndian, win)
+	}
+	return bw.Flush()
+}
+
+// ReadEquityDB reads a database written by WriteEquityDB
+func ReadEquityDB(r io.Reader) (*EquityDB, error) {
+	br := bufio.NewReader(r)
+	header := make([]byte, len(equityDBMagic)+1)
+	if _, err := io.ReadFull(br, header); err != nil {
+		return nil, err
+	}
+	if string(header[:len(equityDBMagic)]) != equityDBMagic {
+		return nil, fmt.Errorf("not an equity database")
+	}
+	if v := header[len(equityDBMagic)]; v != equityDBVersion {
+		return nil, fmt.Errorf("equity database version %d is not supported", v)
+	}
+	var n uint32
+	if err := binary.Read(br, binary.BigEndian, &n); err != nil {
+		return nil, err
+	}
+	db := &EquityDB{results: make(map[string][]uint16, n)}
+	for i := uint32(0); i < n; i++ {
+		key, err := readBytes(br)
+		if err != nil {
+			return nil, fmt.Errorf("spot %d: %s", i, err)
+		}
+		size, err := br.ReadByte()
+		if err != nil {
+			return nil, fmt.Errorf("spot %d: %s", i, err)
+		}
+		win := make([]uint16, size)
+		if err := binary.Read(br, binary.BigEndian, win); err != nil {
+			return nil, fmt.Errorf("spot %d: %s", i, err)
+		}
+		db.results[string(key)] = win
+	}
+	return db, nil
+}
+
+// readBytes reads a length prefixed byte string
+func readBytes(r *bufio.Reader) ([]byte, error) {
+	size, err := r.ReadByte()
+	if err != nil {
+		return nil, err
+	}
+	b := make([]byte, size)
+	_, err = io.ReadFull(r, b)
+	return b, err
+}
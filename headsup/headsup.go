@@ -2,10 +2,12 @@ package headsup
 
 import (
 	"encoding/csv"
+	"errors"
 	"fmt"
 	"os"
 	"strconv"
 	"strings"
+	"sync"
 
 	"github.com/aultimus/gosouth/deck"
 	"github.com/aultimus/gosouth/hand"
@@ -13,6 +15,14 @@ import (
 
 // TODO: Rename this package 'prob'
 
+// ErrBoardSize is wrapped by errors for a board with too many cards
+var ErrBoardSize = errors.New("too many board cards")
+
+const (
+	numHoleCards = 2
+	numCommCards = 5
+)
+
 // Result represents the probability breakdown
 // of a hand unfolding
 type Result struct {
@@ -37,50 +47,85 @@ func (r *Result) String() string {
 
 // Prob given n (1 -> many) initial starting hands calculates the probabilities
 // of the results by simulating every possible deal from the resultant deck.
-// Likely faster to use a lookup table, this function can help generate one
+// Two hands against one another see each of the 1,712,304 boards once. A
+// single hand is played against every hand the opponent could hold on every
+// board, over two billion evaluations preflop, so precompute single hands
+// once and look them up, as an EquityDB does for the flop and turn.
 func Prob(hands ...hand.Hand) (*Result, error) {
+	return ProbBoard(nil, nil, hands...)
+}
+
+// ProbBoard is Prob for a partially dealt board, only the remaining
+// community cards are simulated. Dead cards are known to be out of play
+// and are removed from the deck. Each hand must hold two hole cards and
+// no card may be given twice.
+func ProbBoard(board, dead hand.Hand, hands ...hand.Hand) (*Result, error) {
+	return prob(board, dead, hands, false)
+}
+
+// Equity is Prob with a draw shared between the hands drawing rather than
+// counted in full for each, so each result is that hand's share of the pot
+// and the results add up to 100%
+func Equity(hands ...hand.Hand) (*Result, error) {
+	return prob(nil, nil, hands, true)
+}
+
+// prob counts the wins of the hands over every runout, a draw counts in
+// full for every hand drawing or if split is set is shared between them
+func prob(board, dead hand.Hand, hands []hand.Hand, split bool) (*Result, error) {
 	numResults := len(hands)
 	if len(hands) == 1 {
 		numResults = 2
 	}
 	r := NewResult(numResults)
-	var err error
-	c := make(chan deck.Deck)
-	d := deck.New()
+	if len(hands) == 0 {
+		return r, fmt.Errorf("%w: no hands given", hand.ErrHoleCards)
+	}
+	if err := hand.ValidateHoles(numHoleCards, hands...); err != nil {
+		return r, err
+	}
+	if len(board) > numCommCards {
+		return r, fmt.Errorf("%w: board should have at most %d cards, not %d",
+			ErrBoardSize, numCommCards, len(board))
+	}
 
 	var usedCards hand.Hand
 	for _, h := range hands {
 		usedCards = append(usedCards, h...)
 	}
+	usedCards = append(usedCards, board...)
+	usedCards = append(usedCards, dead...)
 
-	d, err = deck.RemoveMultiple(d, usedCards)
+	// errors wrap card.ErrInvalidCard or card.ErrDuplicateCard
+	used, err := usedCards.Set()
 	if err != nil {
 		return r, err
 	}
-	numCardsToDeal := 5
-	if len(hands) == 1 {
-		numCardsToDeal = 7
-	}
-	go deck.Combs(d, numCardsToDeal, c)
-	count := 0
-	var extraHands []hand.Hand
-	for v := range c {
-		count++
-		var pHands []hand.Hand
-		// generate an opposing hand out of the deal
+	d := deck.Without(deck.New(), used)
+	// with nothing left to deal the one runout is empty
+	runouts := deck.NewCombinations(d, numCommCards-len(board))
+	for runouts.Next() {
+		full := append(append(hand.Hand(nil), board...), runouts.Comb()...)
 		if len(hands) == 1 {
-			extraHands = []hand.Hand{{v[0], v[1]}}
-			v = v[2:]
+			draw := 1.0
+			if split {
+				draw = 0.5
+			}
+			versusRandom(r.Win, full, hands[0], d, draw)
+			continue
 		}
-
-		for _, h := range append(hands, extraHands...) {
-			pHands = append(pHands, hand.Hand(append(v, h...)))
+		var pHands []hand.Hand
+		for _, h := range hands {
+			pHands = append(pHands, append(append(hand.Hand(nil), full...), h...))
 		}
-
 		winners := hand.Showdown(pHands)
-		// draws will add up to over 100% but we are ok with that
-		for i := 0; i < len(winners); i++ {
-			r.Win[i]++
+		// unless split draws will add up to over 100% but we are ok with that
+		share := 1.0
+		if split {
+			share /= float64(len(winners))
+		}
+		for _, w := range winners {
+			r.Win[w] += share
 		}
 	}
 
@@ -95,6 +140,79 @@ func Prob(hands ...hand.Hand) (*Result, error) {
 	return r, nil
 }
 
+// versusRandom plays h against every hole pair left in d once the board
+// is full, counting wins in win[0] and the opponent's wins in win[1] and
+// adding draw to both for a draw
+func versusRandom(win []float64, full, h hand.Hand, d deck.Deck, draw float64) {
+	hero, err := hand.FormHand(append(append(hand.Hand(nil), full...), h...))
+	if err != nil {
+		// the cards were validated
+		panic(err)
+	}
+	runout, _ := full.Set()
+	opponents := deck.NewCombinations(deck.Without(d, runout), numHoleCards)
+	villain := append(make(hand.Hand, 0, len(full)+numHoleCards), full...)
+	for opponents.Next() {
+		v, err := hand.FormHand(append(villain, opponents.Comb()...))
+		if err != nil {
+			panic(err)
+		}
+		switch hand.Compare(hero, v) {
+		case hand.H1Win:
+			win[0]++
+		case hand.H2Win:
+			win[1]++
+		default:
+			win[0] += draw
+			win[1] += draw
+		}
+	}
+}
+
+// Cache remembers the results of ProbBoard, situations equal under
+// a relabelling of the suits share one entry. It is safe for concurrent use.
+type Cache struct {
+	mu      sync.Mutex
+	results map[string][]float64
+}
+
+// NewCache returns an empty cache
+func NewCache() *Cache {
+	return &Cache{results: make(map[string][]float64)}
+}
+
+// ProbBoard is ProbBoard, answered from the cache when an
+// isomorphic situation has been seen before
+func (c *Cache) ProbBoard(board, dead hand.Hand, hands ...hand.Hand) (*Result, error) {
+	groups := append([]hand.Hand{board, dead}, hands...)
+	// keys are only meaningful for valid cards
+	if err := hand.Validate(groups...); err != nil {
+		return nil, err
+	}
+	key := hand.CanonicalKey(groups...)
+	c.mu.Lock()
+	win, ok := c.results[key]
+	c.mu.Unlock()
+	if ok {
+		return &Result{Win: append([]float64(nil), win...)}, nil
+	}
+	r, err := ProbBoard(board, dead, hands...)
+	if err != nil {
+		return nil, err
+	}
+	c.mu.Lock()
+	c.results[key] = append([]float64(nil), r.Win...)
+	c.mu.Unlock()
+	return r, nil
+}
+
+// Len returns the number of results cached
+func (c *Cache) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.results)
+}
+
 func rmWhitespace(s string) string {
 	return strings.Replace(s, " ", "", -1)
 }
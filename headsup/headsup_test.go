@@ -0,0 +1,297 @@
+package headsup
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/aultimus/gosouth/card"
+	"github.com/aultimus/gosouth/deck"
+	"github.com/aultimus/gosouth/hand"
+	"github.com/stretchr/testify/assert"
+)
+
+func parse(a *assert.Assertions, s string) hand.Hand {
+	h, err := hand.Parse(s)
+	a.NoError(err)
+	return h
+}
+
+func TestCache(t *testing.T) {
+	a := assert.New(t)
+	c := NewCache()
+	board := parse(a, "2H 7H 9C QD")
+	r1, err := c.ProbBoard(board, nil, parse(a, "AS KS"), parse(a, "JH TH"))
+	a.NoError(err)
+	a.Equal(1, c.Len())
+
+	// the same spot with spades and hearts swapped is answered from the cache
+	r2, err := c.ProbBoard(parse(a, "2S 7S 9C QD"), nil, parse(a, "AH KH"), parse(a, "JS TS"))
+	a.NoError(err)
+	a.Equal(1, c.Len())
+	a.Equal(r1, r2)
+	want, err := ProbBoard(board, nil, parse(a, "AS KS"), parse(a, "JH TH"))
+	a.NoError(err)
+	a.Equal(want, r2)
+
+	_, err = c.ProbBoard(board, nil, parse(a, "AS KH"), parse(a, "JH TH"))
+	a.NoError(err)
+	a.Equal(2, c.Len())
+
+	// a hand against a random hand is cached under its suits too
+	board = parse(a, "2C 7C 9C QC")
+	r1, err = c.ProbBoard(board, nil, parse(a, "AS AH"))
+	a.NoError(err)
+	a.Equal(3, c.Len())
+	r2, err = c.ProbBoard(board, nil, parse(a, "AD AH"))
+	a.NoError(err)
+	a.Equal(3, c.Len())
+	a.Equal(r1, r2)
+	want, err = ProbBoard(board, nil, parse(a, "AD AH"))
+	a.NoError(err)
+	a.Equal(want, r2)
+}
+
+func TestProbVsRandom(t *testing.T) {
+	a := assert.New(t)
+	// on the river the random hand is every pair left, played one at a time
+	board := parse(a, "2C 7C 9C QC KD")
+	hero := parse(a, "AS AH")
+	r, err := ProbBoard(board, nil, hero)
+	a.NoError(err)
+	used, err := append(append(hand.Hand(nil), board...), hero...).Set()
+	a.NoError(err)
+	want := NewResult(2)
+	for opp := range deck.NewCombinations(deck.Without(deck.New(), used), 2).All() {
+		p, err := ProbBoard(board, nil, hero, hand.Hand(opp))
+		a.NoError(err)
+		// each of these is all or nothing, a draw counts for both
+		want.Win[0] += p.Win[0] / 100
+		want.Win[1] += p.Win[1] / 100
+	}
+	total := want.Win[0] + want.Win[1]
+	a.InDelta(want.Win[0]/total*100, r.Win[0], 1e-9)
+	a.InDelta(want.Win[1]/total*100, r.Win[1], 1e-9)
+
+	// hands equal under a relabelling of the suits have the same equity
+	board = parse(a, "2C 7C 9C QC")
+	for _, pair := range [][2]string{{"AS AH", "AD AH"}, {"8S 8H", "8D 8H"}, {"KS QH", "KH QD"}} {
+		r1, err := ProbBoard(board, nil, parse(a, pair[0]))
+		a.NoError(err)
+		r2, err := ProbBoard(board, nil, parse(a, pair[1]))
+		a.NoError(err)
+		a.Equal(r1, r2, pair)
+	}
+}
+
+// Long running test
+func TestProbVsRandomFlop(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping TestProbVsRandomFlop as in short mode")
+	}
+	a := assert.New(t)
+	board := parse(a, "2C 7C 9C")
+	for _, pair := range [][2]string{{"AS AH", "AD AH"}, {"8S 8H", "8D 8H"}} {
+		r1, err := ProbBoard(board, nil, parse(a, pair[0]))
+		a.NoError(err)
+		r2, err := ProbBoard(board, nil, parse(a, pair[1]))
+		a.NoError(err)
+		a.Equal(r1, r2, pair)
+	}
+}
+
+func TestEquity(t *testing.T) {
+	a := assert.New(t)
+	// the same two cards chop every runout but a spade
+	board := parse(a, "2S 7D 9S QC")
+	h1, h2 := parse(a, "AS KS"), parse(a, "AD KC")
+	var win, lose, tie float64
+	used, err := append(append(append(hand.Hand(nil), board...), h1...), h2...).Set()
+	a.NoError(err)
+	for _, river := range deck.Without(deck.New(), used) {
+		full := append(append(hand.Hand(nil), board...), river)
+		winners := hand.Showdown([]hand.Hand{append(append(hand.Hand(nil), full...), h1...), append(full, h2...)})
+		switch {
+		case len(winners) == 2:
+			tie++
+		case winners[0] == 0:
+			win++
+		default:
+			lose++
+		}
+	}
+	a.True(tie > 0 && win > 0)
+	n := win + lose + tie
+
+	r, err := prob(board, nil, []hand.Hand{h1, h2}, true)
+	a.NoError(err)
+	a.InDelta((win+tie/2)/n*100, r.Win[0], 1e-9)
+	a.InDelta((lose+tie/2)/n*100, r.Win[1], 1e-9)
+	// Prob counts a chop in full for both hands
+	p, err := ProbBoard(board, nil, h1, h2)
+	a.NoError(err)
+	a.InDelta((win+tie)/(n+tie)*100, p.Win[0], 1e-9)
+
+	// against a random hand a draw is half a win too
+	r, err = prob(board, nil, []hand.Hand{h1}, true)
+	a.NoError(err)
+	a.InDelta(100, r.Win[0]+r.Win[1], 1e-9)
+}
+
+func TestSpots(t *testing.T) {
+	a := assert.New(t)
+	count := func(board string, vsRandom bool) int {
+		n := 0
+		for range Spots(parse(a, board), vsRandom) {
+			n++
+		}
+		return n
+	}
+	// a board of four suits and ranks is unchanged by any relabelling
+	a.Equal(1128, count("2C 7D 9H QS", true))
+	// on four spades the other suits are interchangeable
+	a.Less(count("2S 7S 9S QS", true), 1128)
+	a.Equal(1755, len(CanonicalBoards(3)))
+}
+
+// Long running test
+func TestSpotsVsHand(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping TestSpotsVsHand as in short mode")
+	}
+	a := assert.New(t)
+	// every ordered pair of hands on the board is a distinct spot
+	seen := make(map[string]bool)
+	for s := range Spots(parse(a, "2C 7D 9H QS"), false) {
+		a.Equal(2, len(s.Hands))
+		seen[fmt.Sprint(s.Hands)] = true
+	}
+	a.Equal(1128*1035, len(seen))
+}
+
+func TestEquityDB(t *testing.T) {
+	a := assert.New(t)
+	board := parse(a, "2H 7H 9C QD")
+	spots := []Spot{
+		{Board: board, Hands: []hand.Hand{parse(a, "AS KS"), parse(a, "JH TH")}},
+		{Board: board, Hands: []hand.Hand{parse(a, "AH AD")}},
+	}
+	db, err := GenerateEquityDB(func(yield func(Spot) bool) {
+		for _, s := range spots {
+			if !yield(s) {
+				return
+			}
+		}
+	})
+	a.NoError(err)
+	a.Equal(2, db.Len())
+
+	var b bytes.Buffer
+	a.NoError(WriteEquityDB(&b, db))
+	db, err = ReadEquityDB(&b)
+	a.NoError(err)
+	a.Equal(2, db.Len())
+
+	for _, s := range spots {
+		want, err := ProbBoard(s.Board, nil, s.Hands...)
+		a.NoError(err)
+		got, ok := db.Lookup(s.Board, s.Hands...)
+		a.True(ok)
+		for i := range want.Win {
+			a.InDelta(want.Win[i], got.Win[i], 0.01)
+		}
+	}
+	// the spot in other suits is found too
+	r, ok := db.Lookup(parse(a, "2S 7S 9D QC"), parse(a, "AH KH"), parse(a, "JS TS"))
+	a.True(ok)
+	a.Equal(2, len(r.Win))
+	_, ok = db.Lookup(board, parse(a, "AS KH"), parse(a, "JH TH"))
+	a.False(ok)
+
+	_, err = ReadEquityDB(bytes.NewBufferString("GSXX"))
+	a.Error(err)
+}
+
+func TestEquityDBFile(t *testing.T) {
+	a := assert.New(t)
+	// the first spots on the first turn boards, as cmd/equitydb generates
+	boards := CanonicalBoards(4)[:2]
+	var spots []Spot
+	for s := range BoardSpots(boards, true) {
+		if spots = append(spots, s); len(spots) == 4 {
+			break
+		}
+	}
+	db, err := GenerateEquityDB(func(yield func(Spot) bool) {
+		for _, s := range spots {
+			if !yield(s) {
+				return
+			}
+		}
+	})
+	a.NoError(err)
+	a.Equal(len(spots), db.Len())
+
+	name := filepath.Join(t.TempDir(), "turn.gseq")
+	f, err := os.Create(name)
+	a.NoError(err)
+	a.NoError(WriteEquityDB(f, db))
+	a.NoError(f.Close())
+
+	f, err = os.Open(name)
+	a.NoError(err)
+	defer f.Close()
+	read, err := ReadEquityDB(f)
+	a.NoError(err)
+	a.Equal(db.Len(), read.Len())
+	for _, s := range spots {
+		want, ok := db.Lookup(s.Board, s.Hands...)
+		a.True(ok)
+		got, ok := read.Lookup(s.Board, s.Hands...)
+		a.True(ok)
+		a.Equal(want, got)
+	}
+}
+
+func TestProbErrors(t *testing.T) {
+	a := assert.New(t)
+	_, err := Prob(parse(a, "AS KS"), parse(a, "AS QH"))
+	a.ErrorIs(err, card.ErrDuplicateCard)
+	_, err = ProbBoard(parse(a, "KS 2C 3D"), nil, parse(a, "AS KS"), parse(a, "JH QH"))
+	a.ErrorIs(err, card.ErrDuplicateCard)
+	_, err = ProbBoard(nil, parse(a, "QH"), parse(a, "AS KS"), parse(a, "JH QH"))
+	a.ErrorIs(err, card.ErrDuplicateCard)
+	_, err = Prob(parse(a, "AS KS QS"), parse(a, "JH QH"))
+	a.ErrorIs(err, hand.ErrHoleCards)
+	_, err = Prob()
+	a.ErrorIs(err, hand.ErrHoleCards)
+	_, err = ProbBoard(parse(a, "2C 3C 4C 5C 6C 7C"), nil, parse(a, "AS KS"), parse(a, "JH QH"))
+	a.ErrorIs(err, ErrBoardSize)
+	_, err = Prob(parse(a, "AS X"), parse(a, "JH QH"))
+	a.ErrorIs(err, card.ErrInvalidCard)
+
+	r, err := NewCache().ProbBoard(nil, nil, parse(a, "AS KS"), parse(a, "AS QH"))
+	a.ErrorIs(err, card.ErrDuplicateCard)
+	a.Nil(r)
+	r, err = NewCache().ProbBoard(nil, nil)
+	a.ErrorIs(err, hand.ErrHoleCards)
+	a.Nil(r)
+}
+
+// Long running test
+func TestProbWinnerSeat(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping TestProbWinnerSeat as in short mode")
+	}
+	a := assert.New(t)
+	aces := hand.Hand{card.New(card.Ace, card.Spades), card.New(card.Ace, card.Hearts)}
+	trash := hand.Hand{card.New(card.Seven, card.Clubs), card.New(card.Two, card.Diamonds)}
+
+	// the wins are counted for the seat that won, not the first seats
+	r, err := Prob(trash, aces)
+	a.NoError(err)
+	a.Less(r.Win[0], 20.0)
+	a.Greater(r.Win[1], 80.0)
+}
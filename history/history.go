@@ -0,0 +1,136 @@
+package history
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/aultimus/gosouth/game"
+	"github.com/aultimus/gosouth/hand"
+)
+
+// Hand is a completed game along with the details
+// of where and when it was played
+type Hand struct {
+	ID    int64
+	Table string
+	Time  time.Time
+	// Players holds a name for each seat, seats without
+	// a name are written as "Player 1", "Player 2"...
+	Players []string
+	Game    *game.Game
+}
+
+var handNames = []string{
+	"high card",
+	"a pair",
+	"two pair",
+	"three of a kind",
+	"a straight",
+	"a flush",
+	"a full house",
+	"four of a kind",
+	"a straight flush",
+	"a royal flush",
+	"five of a kind",
+}
+
+// check returns an error if the hand cannot be written
+func (h *Hand) check() error {
+	if h.Game == nil || !h.Game.Done() {
+		return fmt.Errorf("hand %d is not complete", h.ID)
+	}
+	if len(h.Players) > len(h.Game.Seats) {
+		return fmt.Errorf("hand %d has %d players for %d seats", h.ID, len(h.Players), len(h.Game.Seats))
+	}
+	return nil
+}
+
+// name of the player in a seat
+func (h *Hand) name(seat int) string {
+	if seat < len(h.Players) && h.Players[seat] != "" {
+		return h.Players[seat]
+	}
+	return fmt.Sprintf("Player %d", seat+1)
+}
+
+// startingStacks returns the stacks before the hand
+func (h *Hand) startingStacks() []int {
+	g := h.Game
+	stacks := make([]int, len(g.Seats))
+	for i, s := range g.Seats {
+		stacks[i] = s.Stack + s.Total - g.Winnings[i]
+	}
+	return stacks
+}
+
+// posts returns the antes and blinds put in by each seat,
+// a seat short of chips posts what it has
+func (h *Hand) posts() (antes, blinds []int) {
+	g := h.Game
+	stacks := h.startingStacks()
+	antes = make([]int, len(stacks))
+	blinds = make([]int, len(stacks))
+	for i := range stacks {
+		antes[i] = min(g.Config.Ante, stacks[i])
+		stacks[i] -= antes[i]
+	}
+	sb, bb := g.SmallBlindSeat(), g.BigBlindSeat()
+	blinds[sb] = min(g.Config.SmallBlind, stacks[sb])
+	blinds[bb] = min(g.Config.BigBlind, stacks[bb])
+	antes[bb] += min(g.Config.BigBlindAnte, stacks[bb]-blinds[bb])
+	return antes, blinds
+}
+
+// returned works out the uncalled chips given back to each seat,
+// the difference between what the seat put in and what it has in the pot
+func (h *Hand) returned() []int {
+	g := h.Game
+	antes, blinds := h.posts()
+	bets := append([]int(nil), blinds...)
+	put := make([]int, len(g.Seats))
+	street := game.Preflop
+	for _, a := range g.Actions {
+		if a.Street != street {
+			street = a.Street
+			bets = make([]int, len(g.Seats))
+		}
+		switch a.Type {
+		case game.Call:
+			put[a.Seat] += a.Amount
+			bets[a.Seat] += a.Amount
+		case game.Bet, game.Raise:
+			put[a.Seat] += a.Amount - bets[a.Seat]
+			bets[a.Seat] = a.Amount
+		}
+	}
+	returned := make([]int, len(g.Seats))
+	for i, s := range g.Seats {
+		returned[i] = antes[i] + blinds[i] + put[i] - s.Total
+	}
+	return returned
+}
+
+// shown returns the seats that showed their cards, none if
+// everyone else folded
+func (h *Hand) shown() []int {
+	var seats []int
+	for i, s := range h.Game.Seats {
+		if !s.Folded {
+			seats = append(seats, i)
+		}
+	}
+	if len(seats) < 2 {
+		return nil
+	}
+	return seats
+}
+
+// describe names the best hand a seat made
+func (h *Hand) describe(seat int) string {
+	cards := append(append(hand.Hand(nil), h.Game.Seats[seat].Hole...), h.Game.Board...)
+	v, err := hand.FormHand(cards)
+	if err != nil {
+		return ""
+	}
+	return handNames[v.Rank]
+}
@@ -0,0 +1,624 @@
+package history
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/aultimus/gosouth/card"
+	"github.com/aultimus/gosouth/deck"
+	"github.com/aultimus/gosouth/game"
+	"github.com/aultimus/gosouth/hand"
+	"github.com/stretchr/testify/assert"
+)
+
+var played = time.Date(2024, 3, 9, 21, 15, 0, 0, time.UTC)
+
+func mkHand(cards ...string) hand.Hand {
+	var h hand.Hand
+	for _, s := range cards {
+		h = append(h, card.New(card.RANK(s[:1]), card.SUIT(s[1:])))
+	}
+	return h
+}
+
+// stacked returns a deck that deals the given hole cards, listed by seat,
+// and board for a game with the button on the last seat
+func stacked(holes [][]string, board []string) deck.Deck {
+	var d deck.Deck
+	for c := 0; c < 2; c++ {
+		for _, hole := range holes {
+			d = append(d, mkHand(hole[c])...)
+		}
+	}
+	b := mkHand(board...)
+	burns := mkHand("2H", "2D", "2S")
+	d = append(d, burns[0], b[0], b[1], b[2], burns[1], b[3], burns[2], b[4])
+	rest, err := deck.RemoveMultiple(deck.New(), d)
+	if err != nil {
+		panic(err)
+	}
+	return append(d, rest...)
+}
+
+func act(a *assert.Assertions, g *game.Game, seat int, t game.ACTION, amount int) {
+	a.NoError(g.Act(game.Action{Seat: seat, Type: t, Amount: amount}))
+}
+
+// sidePots plays a three way hand where the button and
+// small blind are all in for different amounts
+func sidePots(a *assert.Assertions) *Hand {
+	d := stacked([][]string{{"KS", "KD"}, {"QS", "QD"}, {"AS", "AD"}},
+		[]string{"3C", "7D", "9H", "TS", "4D"})
+	g, err := game.New(game.Config{SmallBlind: 1, BigBlind: 2, Ante: 1}, []int{100, 200, 50}, 2, d)
+	a.NoError(err)
+	act(a, g, 2, game.Raise, 6)
+	act(a, g, 0, game.Call, 0)
+	act(a, g, 1, game.Call, 0)
+	act(a, g, 0, game.Check, 0)
+	act(a, g, 1, game.Bet, 10)
+	act(a, g, 2, game.Raise, 43)
+	act(a, g, 0, game.Raise, 93)
+	act(a, g, 1, game.Call, 0)
+	a.True(g.Done())
+	return &Hand{ID: 42, Table: "Alpha", Time: played, Players: []string{"ann", "bob", "cat"}, Game: g}
+}
+
+func TestWriteText(t *testing.T) {
+	a := assert.New(t)
+	var b bytes.Buffer
+	a.NoError(WriteText(&b, sidePots(a)))
+	a.Equal(`PokerStars Hand #42: Hold'em No Limit (1/2) - 2024/03/09 21:15:00 UTC
+Table 'Alpha' 3-max Seat #3 is the button
+Seat 1: ann (100 in chips)
+Seat 2: bob (200 in chips)
+Seat 3: cat (50 in chips)
+ann: posts the ante 1
+bob: posts the ante 1
+cat: posts the ante 1
+ann: posts small blind 1
+bob: posts big blind 2
+*** HOLE CARDS ***
+Dealt to ann [KS KD]
+Dealt to bob [QS QD]
+Dealt to cat [AS AD]
+cat: raises 4 to 6
+ann: calls 5
+bob: calls 4
+*** FLOP *** [3C 7D 9H]
+ann: checks
+bob: bets 10
+cat: raises 33 to 43 and is all-in
+ann: raises 50 to 93 and is all-in
+bob: calls 83
+*** TURN *** [3C 7D 9H] [TS]
+*** RIVER *** [3C 7D 9H TS] [4D]
+*** SHOW DOWN ***
+ann: shows [KS KD] (a pair)
+bob: shows [QS QD] (a pair)
+cat: shows [AS AD] (a pair)
+cat collected 150 from main pot
+ann collected 100 from side pot-1
+*** SUMMARY ***
+Total pot 250 Main pot 150. Side pot-1 100. | Rake 0
+Board [3C 7D 9H TS 4D]
+Seat 1: ann (small blind) showed [KS KD] and won (100) with a pair
+Seat 2: bob (big blind) showed [QS QD] and lost with a pair
+Seat 3: cat (button) showed [AS AD] and won (150) with a pair
+`, b.String())
+}
+
+func TestBigBlindAnte(t *testing.T) {
+	a := assert.New(t)
+	g, err := game.New(game.Config{SmallBlind: 1, BigBlind: 2, BigBlindAnte: 2}, []int{100, 100, 100}, 0, deck.New())
+	a.NoError(err)
+	act(a, g, 0, game.Fold, 0)
+	act(a, g, 1, game.Fold, 0)
+	h := &Hand{ID: 8, Time: played, Players: []string{"ann", "bob", "cat"}, Game: g}
+	var b bytes.Buffer
+	a.NoError(WriteText(&b, h))
+	a.Contains(b.String(), "cat: posts the ante 2\nbob: posts small blind 1\ncat: posts big blind 2\n")
+	recs, err := ParseText(&b)
+	a.NoError(err)
+	a.Equal(2, recs[0].Players[2].Ante)
+	a.Equal(1, recs[0].Net(2))
+
+	b.Reset()
+	a.NoError(WritePHH(&b, h))
+	a.Contains(b.String(), "antes = [0, 2, 0]\n")
+	recs, err = ParsePHH(&b)
+	a.NoError(err)
+	a.Equal(1, recs[0].Net(1))
+}
+
+func TestWriteTextFolds(t *testing.T) {
+	a := assert.New(t)
+	g, err := game.New(game.Config{SmallBlind: 1, BigBlind: 2}, []int{100, 100}, 0, deck.New())
+	a.NoError(err)
+	act(a, g, 0, game.Raise, 6)
+	act(a, g, 1, game.Call, 0)
+	act(a, g, 1, game.Bet, 8)
+	act(a, g, 0, game.Fold, 0)
+	var b bytes.Buffer
+	a.NoError(WriteText(&b, &Hand{ID: 7, Time: played, Players: []string{"ann"}, Game: g}))
+	a.Equal(`PokerStars Hand #7: Hold'em No Limit (1/2) - 2024/03/09 21:15:00 UTC
+Table '' 2-max Seat #1 is the button
+Seat 1: ann (100 in chips)
+Seat 2: Player 2 (100 in chips)
+ann: posts small blind 1
+Player 2: posts big blind 2
+*** HOLE CARDS ***
+Dealt to Player 2 [2C 4C]
+Dealt to ann [3C 5C]
+ann: raises 4 to 6
+Player 2: calls 4
+*** FLOP *** [7C 8C 9C]
+Player 2: bets 8
+ann: folds
+Uncalled bet (8) returned to Player 2
+Player 2 collected 12 from pot
+*** SUMMARY ***
+Total pot 12 | Rake 0
+Board [7C 8C 9C]
+Seat 1: ann (button) (small blind) folded on the Flop
+Seat 2: Player 2 (big blind) collected (12)
+`, b.String())
+
+	g, err = game.New(game.Config{SmallBlind: 1, BigBlind: 2}, []int{100, 100}, 0, deck.New())
+	a.NoError(err)
+	a.Error(WriteText(&b, &Hand{Game: g}))
+}
+
+func TestWritePHH(t *testing.T) {
+	a := assert.New(t)
+	var b bytes.Buffer
+	a.NoError(WritePHH(&b, sidePots(a)))
+	a.Equal(`variant = "NT"
+ante_trimming_status = true
+antes = [1, 1, 1]
+blinds_or_straddles = [1, 2, 0]
+min_bet = 2
+starting_stacks = [100, 200, 50]
+actions = [
+  "d dh p1 KsKd",
+  "d dh p2 QsQd",
+  "d dh p3 AsAd",
+  "p3 cbr 6",
+  "p1 cc",
+  "p2 cc",
+  "d db 3c7d9h",
+  "p1 cc",
+  "p2 cbr 10",
+  "p3 cbr 43",
+  "p1 cbr 93",
+  "p2 cc",
+  "d db Ts",
+  "d db 4d",
+  "p1 sm KsKd",
+  "p2 sm QsQd",
+  "p3 sm AsAd",
+]
+hand = 42
+table = "Alpha"
+seat_count = 3
+seats = [1, 2, 3]
+players = ["ann", "bob", "cat"]
+finishing_stacks = [100, 100, 150]
+winnings = [100, 0, 150]
+year = 2024
+month = 3
+day = 9
+time = 21:15:00
+time_zone = "UTC"
+`, b.String())
+
+	// heads up the big blind is first
+	g, err := game.New(game.Config{SmallBlind: 1, BigBlind: 2, Structure: game.FixedLimit{SmallBet: 2, BigBet: 4}},
+		[]int{100, 100}, 0, deck.New())
+	a.NoError(err)
+	act(a, g, 0, game.Fold, 0)
+	b.Reset()
+	a.NoError(WritePHH(&b, &Hand{Game: g}))
+	a.Equal(`variant = "FT"
+ante_trimming_status = true
+antes = [0, 0]
+blinds_or_straddles = [2, 1]
+small_bet = 2
+big_bet = 4
+starting_stacks = [100, 100]
+actions = [
+  "d dh p1 2c4c",
+  "d dh p2 3c5c",
+  "p2 f",
+]
+seat_count = 2
+seats = [2, 1]
+players = ["Player 2", "Player 1"]
+finishing_stacks = [101, 99]
+winnings = [2, 0]
+`, b.String())
+
+	g, err = game.New(game.Config{SmallBlind: 1, BigBlind: 2, Structure: game.PotLimit{}}, []int{100, 100}, 0, deck.New())
+	a.NoError(err)
+	act(a, g, 0, game.Fold, 0)
+	a.Error(WritePHH(&b, &Hand{Game: g}))
+}
+
+func TestParseText(t *testing.T) {
+	a := assert.New(t)
+	var b bytes.Buffer
+	h := sidePots(a)
+	a.NoError(WriteText(&b, h))
+	b.WriteString("\n\n")
+	a.NoError(WriteText(&b, h))
+	records, err := ParseText(&b)
+	a.NoError(err)
+	a.Equal(2, len(records))
+	r := records[0]
+	a.Equal(int64(42), r.ID)
+	a.Equal("Alpha", r.Table)
+	a.True(played.Equal(r.Time))
+	a.Equal("Hold'em No Limit", r.Game)
+	a.Equal(1, r.SmallBlind)
+	a.Equal(2, r.BigBlind)
+	a.Equal(1, r.Ante)
+	a.Equal(2, r.Button)
+	a.Equal(&Player{Name: "ann", Seat: 1, Stack: 100, Ante: 1, Blind: 1,
+		Hole: mkHand("KS", "KD"), Shown: true, Won: 100}, r.Players[0])
+	a.Equal(&Player{Name: "bob", Seat: 2, Stack: 200, Ante: 1, Blind: 2,
+		Hole: mkHand("QS", "QD"), Shown: true}, r.Players[1])
+	a.Equal(150, r.Players[2].Won)
+	a.Equal(mkHand("3C", "7D", "9H", "TS", "4D"), r.Board)
+	a.Equal(h.Game.Actions, r.Actions)
+	a.Equal("SB", r.Position(0))
+	a.Equal("BB", r.Position(1))
+	a.Equal("BTN", r.Position(2))
+}
+
+const starsHand = `PokerStars Hand #208741963512: Hold'em No Limit ($0.05/$0.10 USD) - 2020/01/15 9:04:35 ET [2020/01/15 15:04:35 CET]
+Table 'Aaltje II' 6-max Seat #4 is the button
+Seat 1: Hero: the (Great) ($10 in chips)
+Seat 2: villain ($12.35 in chips)
+Seat 4: kate ($7.20 in chips) is sitting out
+Seat 6: lou ($10.05 in chips)
+lou: posts small blind $0.05
+Hero: the (Great): posts big blind $0.10
+*** HOLE CARDS ***
+Dealt to Hero: the (Great) [Ah Kh]
+villain: raises $0.20 to $0.30
+kate: folds
+lou: folds
+Hero: the (Great): raises $0.70 to $1
+villain said, "nh"
+villain: calls $0.70
+*** FLOP *** [2h 7h Jc]
+Hero: the (Great): bets $1.50
+villain: raises $10.85 to $12.35 and is all-in
+Hero: the (Great): calls $7.50 and is all-in
+Uncalled bet ($3.35) returned to villain
+*** TURN *** [2h 7h Jc] [5d]
+*** RIVER *** [2h 7h Jc 5d] [3h]
+*** SHOW DOWN ***
+Hero: the (Great): shows [Ah Kh] (a flush, Ace high)
+villain: shows [Jd Js] (three of a kind, Jacks)
+Hero: the (Great) collected $19.05 from pot
+*** SUMMARY ***
+Total pot $20.05 | Rake $0.95
+Board [2h 7h Jc 5d 3h]
+Seat 1: Hero: the (Great) (big blind) showed [Ah Kh] and won ($19.05) with a flush, Ace high
+`
+
+func TestParseTextStars(t *testing.T) {
+	a := assert.New(t)
+	records, err := ParseText(bytes.NewBufferString(starsHand))
+	a.NoError(err)
+	a.Equal(1, len(records))
+	r := records[0]
+	a.Equal(int64(208741963512), r.ID)
+	a.Equal("$", r.Currency)
+	a.Equal(5, r.SmallBlind)
+	a.Equal(10, r.BigBlind)
+	// 9:04 ET is 14:04 UTC in January
+	a.True(time.Date(2020, 1, 15, 14, 4, 35, 0, time.UTC).Equal(r.Time), r.Time)
+	a.Equal(4, len(r.Players))
+	a.Equal(2, r.Button)
+	a.Equal(0, r.Player("Hero: the (Great)"))
+	a.Equal(1000, r.Players[0].Stack)
+	a.Equal(1235, r.Players[1].Stack)
+	a.Equal("UTG", r.Position(1))
+	a.Equal("BB", r.Position(0))
+	a.Equal(1905, r.Players[0].Won)
+	a.Equal(mkHand("JD", "JS"), r.Players[1].Hole)
+	a.Equal(game.Action{Seat: 1, Street: game.Preflop, Type: game.Raise, Amount: 30}, r.Actions[0])
+	a.Equal(game.Action{Seat: 1, Street: game.Flop, Type: game.Raise, Amount: 1235, AllIn: true}, r.Actions[6])
+	a.Equal(8, len(r.Actions))
+}
+
+func TestParseTextErrors(t *testing.T) {
+	a := assert.New(t)
+	for _, tc := range []struct {
+		line int
+		text string
+	}{
+		{1, "Table 'x' 2-max Seat #1 is the button"},
+		{1, "PokerStars Hand #1: Hold'em No Limit 1/2 - 2024/03/09 21:15:00 UTC"},
+		{1, "PokerStars Hand #1: Hold'em No Limit (1/2) - yesterday"},
+		{3, "PokerStars Hand #1: Hold'em No Limit (1/2) - 2024/03/09 21:15:00 UTC\n\nSeat 1: ann (lots in chips)"},
+		{3, "PokerStars Hand #1: Hold'em No Limit (1/2) - 2024/03/09 21:15:00 UTC\nSeat 1: ann (10 in chips)\nann: raises to 5"},
+		{3, "PokerStars Hand #1: Hold'em No Limit (1/2) - 2024/03/09 21:15:00 UTC\nSeat 1: ann (10 in chips)\nann: calls"},
+		{3, "PokerStars Hand #1: Hold'em No Limit (1/2) - 2024/03/09 21:15:00 UTC\nSeat 1: ann (10 in chips)\nDealt to bob [AS KS]"},
+		{3, "PokerStars Hand #1: Hold'em No Limit (1/2) - 2024/03/09 21:15:00 UTC\nSeat 1: ann (10 in chips)\nDealt to ann [AS KZ]"},
+		{2, "PokerStars Hand #1: Hold'em No Limit (1/2) - 2024/03/09 21:15:00 UTC\n*** FLOP *** [AS KS]"},
+		{2, "PokerStars Hand #1: Hold'em No Limit (1/2) - 2024/03/09 21:15:00 UTC\n*** FIRST FLOP *** [AS KS QS]"},
+	} {
+		_, err := ParseText(bytes.NewBufferString(tc.text))
+		if a.Error(err, tc.text) {
+			a.Contains(err.Error(), fmt.Sprintf("line %d:", tc.line), tc.text)
+		}
+	}
+}
+
+func TestParsePHH(t *testing.T) {
+	a := assert.New(t)
+	var b bytes.Buffer
+	h := sidePots(a)
+	a.NoError(WritePHH(&b, h))
+	records, err := ParsePHH(&b)
+	a.NoError(err)
+	a.Equal(1, len(records))
+	r := records[0]
+	a.Equal(int64(42), r.ID)
+	a.Equal("Alpha", r.Table)
+	a.True(played.Equal(r.Time))
+	a.Equal("Hold'em No Limit", r.Game)
+	a.Equal(2, r.Button)
+	a.Equal(&Player{Name: "ann", Seat: 1, Stack: 100, Ante: 1, Blind: 1,
+		Hole: mkHand("KS", "KD"), Shown: true, Won: 100}, r.Players[0])
+	a.Equal(mkHand("3C", "7D", "9H", "TS", "4D"), r.Board)
+	a.Equal(h.Game.Actions, r.Actions)
+}
+
+const phhHands = `# two hands from a .phhs file
+[1]
+variant = 'NT'
+antes = [0, 0, 0]
+blinds_or_straddles = [50, 100, 0]
+min_bet = 100
+starting_stacks = [10_000, 5_000, 2_500]
+actions = [
+  # preflop
+  'd dh p1 ????',
+  'd dh p2 ????',
+  'd dh p3 7s7c',
+  'p3 cbr 300', 'p1 f', 'p2 cc', # a call
+  'd db Td9h2c',
+  'p2 cc',
+  'p3 cbr 2200',
+  'p2 cc',
+  'd db 2d',
+  'd db As',
+  'p2 sm 9s9c',
+  'p3 sm 7s7c',
+]
+finishing_stacks = [9_950, 7_550, 0]
+players = ["sb", "bb", "btn"]
+
+[2]
+variant = "FT"
+antes = [0, 0]
+blinds_or_straddles = [2, 1]
+small_bet = 2
+big_bet = 4
+starting_stacks = [100, 100]
+actions = ["p2 f"]
+`
+
+func TestParsePHHFile(t *testing.T) {
+	a := assert.New(t)
+	records, err := ParsePHH(bytes.NewBufferString(phhHands))
+	a.NoError(err)
+	a.Equal(2, len(records))
+	r := records[0]
+	a.Equal(50, r.SmallBlind)
+	a.Equal(100, r.BigBlind)
+	a.Nil(r.Players[0].Hole)
+	a.Equal(mkHand("9S", "9C"), r.Players[1].Hole)
+	a.True(r.Players[1].Shown)
+	a.Equal([]game.Action{
+		{Seat: 2, Street: game.Preflop, Type: game.Raise, Amount: 300},
+		{Seat: 0, Street: game.Preflop, Type: game.Fold},
+		{Seat: 1, Street: game.Preflop, Type: game.Call, Amount: 200},
+		{Seat: 1, Street: game.Flop, Type: game.Check},
+		{Seat: 2, Street: game.Flop, Type: game.Bet, Amount: 2200, AllIn: true},
+		{Seat: 1, Street: game.Flop, Type: game.Call, Amount: 2200},
+	}, r.Actions)
+	a.Equal(0, r.Players[0].Won)
+	a.Equal(5050, r.Players[1].Won)
+	a.Equal(0, r.Players[2].Won)
+	a.True(r.Time.IsZero())
+
+	r = records[1]
+	a.Equal(1, r.SmallBlind)
+	a.Equal(2, r.BigBlind)
+	a.Equal("BTN", r.Position(1))
+	a.Equal("p2", r.Players[1].Name)
+}
+
+func TestParsePHHErrors(t *testing.T) {
+	a := assert.New(t)
+	valid := "variant = \"NT\"\nantes = [0, 0]\nblinds_or_straddles = [2, 1]\nstarting_stacks = [100, 100]\n"
+	for _, tc := range []struct {
+		line int
+		text string
+	}{
+		{1, "variant = \"PO\nantes = [0, 0]"},
+		{1, "variant = 'F7S'\nantes = [0, 0]\nblinds_or_straddles = [2, 1]\nstarting_stacks = [100, 100]\nactions = []"},
+		{2, "variant = 'NT'\nantes = [0]\nblinds_or_straddles = [2, 1]\nstarting_stacks = [100, 100]\nactions = []"},
+		{3, "variant = 'NT'\nantes = [0, 0]\nblinds_or_straddles = [2, 1 2]"},
+		{5, valid + "actions = ['d dh p3 AsKs']"},
+		{7, valid + "actions = [\n'p1 cc',\n'p2 cbr 500']"},
+		{5, valid + "actions = ['p2 cbr 1']"},
+		{5, valid + "actions = ['d db AsKs']"},
+		{5, valid + "actions = ['p1 sd']"},
+		{5, valid + "actions = ['d dh p1 AsKz']"},
+		{6, valid + "actions = []\nvariant = 'NT'"},
+		{6, valid + "actions = []\nhand = 1 2"},
+	} {
+		_, err := ParsePHH(bytes.NewBufferString(tc.text))
+		if a.Error(err, tc.text) {
+			a.Contains(err.Error(), fmt.Sprintf("line %d:", tc.line), tc.text)
+		}
+	}
+	_, err := ParsePHH(bytes.NewBufferString(valid))
+	a.EqualError(err, "line 1: missing actions")
+}
+
+func TestNet(t *testing.T) {
+	a := assert.New(t)
+	g, err := game.New(game.Config{SmallBlind: 1, BigBlind: 2, Ante: 1}, []int{100, 100}, 0, deck.New())
+	a.NoError(err)
+	act(a, g, 0, game.Raise, 6)
+	act(a, g, 1, game.Call, 0)
+	act(a, g, 1, game.Bet, 8)
+	act(a, g, 0, game.Fold, 0)
+	h := &Hand{ID: 7, Time: played, Game: g}
+
+	var text, phh bytes.Buffer
+	a.NoError(WriteText(&text, h))
+	a.NoError(WritePHH(&phh, h))
+	fromText, err := ParseText(&text)
+	a.NoError(err)
+	fromPHH, err := ParsePHH(&phh)
+	a.NoError(err)
+	// PHH lists the big blind first heads up
+	for _, r := range []*Record{fromText[0], fromPHH[0]} {
+		button, bb := r.Player("Player 1"), r.Player("Player 2")
+		a.Equal(8, r.Players[bb].Returned)
+		a.Equal(7, r.Invested(button))
+		a.Equal(7, r.Invested(bb))
+		a.Equal(-7, r.Net(button))
+		a.Equal(7, r.Net(bb))
+	}
+}
+
+func TestWriteTimeZones(t *testing.T) {
+	a := assert.New(t)
+	h := sidePots(a)
+	// an abbreviated zone is written in UTC, a day later here
+	h.Time = time.Date(2024, 7, 4, 21, 15, 0, 0, time.FixedZone("EDT", -4*60*60))
+	var b bytes.Buffer
+	a.NoError(WritePHH(&b, h))
+	a.Contains(b.String(), "year = 2024\nmonth = 7\nday = 5\ntime = 01:15:00\ntime_zone = \"UTC\"\n")
+	b.Reset()
+	a.NoError(WriteText(&b, h))
+	a.Contains(b.String(), " - 2024/07/05 01:15:00 UTC\n")
+
+	// an IANA zone is kept
+	loc, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Skip("Skipping IANA zone as there is no zone database")
+	}
+	h.Time = time.Date(2024, 7, 4, 21, 15, 0, 0, loc)
+	b.Reset()
+	a.NoError(WritePHH(&b, h))
+	a.Contains(b.String(), "day = 4\ntime = 21:15:00\ntime_zone = \"America/New_York\"\n")
+}
+
+func TestTimeRoundTrip(t *testing.T) {
+	a := assert.New(t)
+	h := sidePots(a)
+	zones := []*time.Location{time.FixedZone("EDT", -4*60*60), time.FixedZone("", 5*60*60+30*60)}
+	if loc, err := time.LoadLocation("America/New_York"); err == nil {
+		zones = append(zones, loc)
+	}
+	for _, loc := range zones {
+		h.Time = time.Date(2024, 7, 4, 21, 15, 0, 0, loc)
+		var text, phh bytes.Buffer
+		a.NoError(WriteText(&text, h))
+		a.NoError(WritePHH(&phh, h))
+		fromText, err := ParseText(&text)
+		a.NoError(err, loc.String())
+		fromPHH, err := ParsePHH(&phh)
+		a.NoError(err, loc.String())
+		a.True(h.Time.Equal(fromText[0].Time), "%s read back as %s", h.Time, fromText[0].Time)
+		a.True(h.Time.Equal(fromPHH[0].Time), "%s read back as %s", h.Time, fromPHH[0].Time)
+	}
+}
+
+func TestParseTimeZones(t *testing.T) {
+	a := assert.New(t)
+	header := "PokerStars Hand #1: Hold'em No Limit (1/2) - %s\nSeat 1: ann (10 in chips)\n"
+	for when, want := range map[string]time.Time{
+		"2024/07/04 21:15:00 EDT":                         time.Date(2024, 7, 5, 1, 15, 0, 0, time.UTC),
+		"2024/07/04 21:15:00 ET":                          time.Date(2024, 7, 5, 1, 15, 0, 0, time.UTC),
+		"2024/01/04 21:15:00 ET":                          time.Date(2024, 1, 5, 2, 15, 0, 0, time.UTC),
+		"2024/01/04 21:15:00 UTC":                         time.Date(2024, 1, 4, 21, 15, 0, 0, time.UTC),
+		"2024/01/05 3:15:00 CET [2024/01/04 21:15:00 ET]": time.Date(2024, 1, 5, 2, 15, 0, 0, time.UTC),
+		"2024/01/05 3:15:00 ZZZ [2024/01/04 21:15:00 ET]": time.Date(2024, 1, 5, 2, 15, 0, 0, time.UTC),
+		"2024/01/05 3:15:00 ZZZ":                          time.Date(2024, 1, 5, 3, 15, 0, 0, time.UTC),
+		"2024/01/05 3:15:00 ZZZ [2024/01/05 3:15:00 ZZZ]": time.Date(2024, 1, 5, 3, 15, 0, 0, time.UTC),
+	} {
+		records, err := ParseText(bytes.NewBufferString(fmt.Sprintf(header, when)))
+		if a.NoError(err, when) {
+			a.True(want.Equal(records[0].Time), "%s read as %s", when, records[0].Time)
+		}
+	}
+
+	phh := "variant = 'NT'\nantes = [0, 0]\nblinds_or_straddles = [2, 1]\nstarting_stacks = [100, 100]\nactions = []\n" +
+		"year = 2024\nmonth = 7\nday = 4\ntime = 21:15:00\ntime_zone = '%s'\n"
+	records, err := ParsePHH(bytes.NewBufferString(fmt.Sprintf(phh, "EDT")))
+	a.NoError(err)
+	a.True(time.Date(2024, 7, 5, 1, 15, 0, 0, time.UTC).Equal(records[0].Time))
+	_, err = ParsePHH(bytes.NewBufferString(fmt.Sprintf(phh, "Mars/Olympus")))
+	a.ErrorContains(err, "line 10: unknown time zone")
+}
+
+func TestUSDaylight(t *testing.T) {
+	a := assert.New(t)
+	// 2024 saving time ran from March 10th to November 3rd
+	a.False(usDaylight(time.Date(2024, 3, 10, 1, 59, 0, 0, time.UTC)))
+	a.True(usDaylight(time.Date(2024, 3, 10, 2, 0, 0, 0, time.UTC)))
+	a.True(usDaylight(time.Date(2024, 11, 3, 1, 59, 0, 0, time.UTC)))
+	a.False(usDaylight(time.Date(2024, 11, 3, 2, 0, 0, 0, time.UTC)))
+	a.False(usDaylight(time.Date(2024, 1, 4, 21, 15, 0, 0, time.UTC)))
+}
+
+func TestParsePHHUnsupported(t *testing.T) {
+	a := assert.New(t)
+	// Omaha deals four hole cards, which a hold'em record cannot hold
+	for _, v := range []string{"PO", "FO/8", "F7S"} {
+		_, err := ParsePHH(bytes.NewBufferString("\nvariant = '" + v + "'\nantes = [0, 0]\nblinds_or_straddles = [2, 1]\nstarting_stacks = [100, 100]\nactions = []"))
+		a.ErrorContains(err, "line 2: variant "+v+" is not supported")
+	}
+
+	// a missing key is reported at the start of its hand
+	_, err := ParsePHH(bytes.NewBufferString("[1]\nvariant = 'NT'\nantes = [0, 0]\nblinds_or_straddles = [2, 1]\nstarting_stacks = [100, 100]\nactions = []\n\n[2]\nvariant = 'NT'\n"))
+	a.EqualError(err, "line 8: missing starting_stacks")
+}
+
+func TestParseTextUncalled(t *testing.T) {
+	a := assert.New(t)
+	records, err := ParseText(bytes.NewBufferString(`PokerStars Hand #9: Hold'em No Limit ($0.05/$0.10 USD) - 2020/01/15 9:04:35 ET
+Table 'Ceres' 6-max Seat #1 is the button
+Seat 1: ann ($10 in chips)
+Seat 2: bob ($10 in chips)
+ann: posts small blind $0.05
+bob: posts big blind $0.10
+*** HOLE CARDS ***
+ann: raises $0.20 to $0.30
+bob: folds
+Uncalled bet ($0.20) returned to ann
+ann collected $0.20 from pot
+ann: doesn't show hand
+*** SUMMARY ***
+Total pot $0.20 | Rake $0
+Seat 1: ann (button) (small blind) collected ($0.20)
+`))
+	a.NoError(err)
+	r := records[0]
+	a.Equal(20, r.Players[0].Returned)
+	a.Equal(20, r.Players[0].Won)
+	a.Equal(10, r.Net(0))
+	a.Equal(-10, r.Net(1))
+}
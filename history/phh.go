@@ -0,0 +1,728 @@
+package history
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/aultimus/gosouth/game"
+	"github.com/aultimus/gosouth/hand"
+)
+
+// phhCards writes cards back to back as PHH does, the suit in lower case
+func phhCards(h hand.Hand) string {
+	var b strings.Builder
+	for _, c := range h {
+		s := c.String()
+		b.WriteString(s[:len(s)-1] + strings.ToLower(s[len(s)-1:]))
+	}
+	return b.String()
+}
+
+func tomlInts(v []int) string {
+	s := make([]string, len(v))
+	for i, n := range v {
+		s[i] = fmt.Sprint(n)
+	}
+	return "[" + strings.Join(s, ", ") + "]"
+}
+
+func tomlStrings(v []string) string {
+	s := make([]string, len(v))
+	for i, str := range v {
+		s[i] = fmt.Sprintf("%q", str)
+	}
+	return "[" + strings.Join(s, ", ") + "]"
+}
+
+// phhVariant returns the PHH variant code for a betting structure
+func phhVariant(s game.Structure) (string, error) {
+	switch s.(type) {
+	case game.NoLimit:
+		return "NT", nil
+	case game.FixedLimit:
+		return "FT", nil
+	}
+	return "", fmt.Errorf("PHH has no variant for %s Texas hold'em", s.Name())
+}
+
+// WritePHH writes a completed hand in the Poker Hand History TOML format.
+// PHH orders players from the seat after the button, so heads up the big
+// blind is p1 and the blinds are written in reverse.
+func WritePHH(w io.Writer, h *Hand) error {
+	if err := h.check(); err != nil {
+		return err
+	}
+	g := h.Game
+	variant, err := phhVariant(g.Config.Structure)
+	if err != nil {
+		return err
+	}
+
+	n := len(g.Seats)
+	order := make([]int, n)
+	player := make([]int, n)
+	for i := range order {
+		order[i] = (g.Button + 1 + i) % n
+		player[order[i]] = i + 1
+	}
+	// byPlayer reorders per seat values into player order
+	byPlayer := func(v []int) []int {
+		p := make([]int, n)
+		for i, seat := range order {
+			p[i] = v[seat]
+		}
+		return p
+	}
+
+	antes := make([]int, n)
+	blinds := make([]int, n)
+	seats := make([]int, n)
+	finishing := make([]int, n)
+	for i, s := range g.Seats {
+		antes[i] = g.Config.Ante
+		seats[i] = i + 1
+		finishing[i] = s.Stack
+	}
+	blinds[g.SmallBlindSeat()] = g.Config.SmallBlind
+	blinds[g.BigBlindSeat()] = g.Config.BigBlind
+	antes[g.BigBlindSeat()] += g.Config.BigBlindAnte
+	names := make([]string, n)
+	for i, seat := range order {
+		names[i] = h.name(seat)
+	}
+
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "variant = %q\n", variant)
+	b.WriteString("ante_trimming_status = true\n")
+	fmt.Fprintf(&b, "antes = %s\n", tomlInts(byPlayer(antes)))
+	fmt.Fprintf(&b, "blinds_or_straddles = %s\n", tomlInts(byPlayer(blinds)))
+	switch s := g.Config.Structure.(type) {
+	case game.FixedLimit:
+		fmt.Fprintf(&b, "small_bet = %d\nbig_bet = %d\n", s.SmallBet, s.BigBet)
+	default:
+		fmt.Fprintf(&b, "min_bet = %d\n", g.Config.BigBlind)
+	}
+	fmt.Fprintf(&b, "starting_stacks = %s\n", tomlInts(byPlayer(h.startingStacks())))
+
+	b.WriteString("actions = [\n")
+	writeAction := func(format string, args ...interface{}) {
+		fmt.Fprintf(&b, "  %q,\n", fmt.Sprintf(format, args...))
+	}
+	for i, seat := range order {
+		writeAction("d dh p%d %s", i+1, phhCards(g.Seats[seat].Hole))
+	}
+	for street := game.Preflop; street <= game.River; street++ {
+		switch {
+		case street == game.Flop && len(g.Board) >= 3:
+			writeAction("d db %s", phhCards(g.Board[:3]))
+		case street > game.Flop && len(g.Board) >= int(street)+2:
+			writeAction("d db %s", phhCards(g.Board[street+1:street+2]))
+		}
+		for _, a := range g.Actions {
+			if a.Street != street {
+				continue
+			}
+			switch a.Type {
+			case game.Fold:
+				writeAction("p%d f", player[a.Seat])
+			case game.Check, game.Call:
+				writeAction("p%d cc", player[a.Seat])
+			case game.Bet, game.Raise:
+				writeAction("p%d cbr %d", player[a.Seat], a.Amount)
+			}
+		}
+	}
+	shown := make(map[int]bool)
+	for _, seat := range h.shown() {
+		shown[seat] = true
+	}
+	for i, seat := range order {
+		if shown[seat] {
+			writeAction("p%d sm %s", i+1, phhCards(g.Seats[seat].Hole))
+		}
+	}
+	b.WriteString("]\n")
+
+	if h.ID != 0 {
+		fmt.Fprintf(&b, "hand = %d\n", h.ID)
+	}
+	if h.Table != "" {
+		fmt.Fprintf(&b, "table = %q\n", h.Table)
+	}
+	fmt.Fprintf(&b, "seat_count = %d\n", n)
+	fmt.Fprintf(&b, "seats = %s\n", tomlInts(byPlayer(seats)))
+	fmt.Fprintf(&b, "players = %s\n", tomlStrings(names))
+	fmt.Fprintf(&b, "finishing_stacks = %s\n", tomlInts(byPlayer(finishing)))
+	fmt.Fprintf(&b, "winnings = %s\n", tomlInts(byPlayer(g.Winnings)))
+	if !h.Time.IsZero() {
+		t := h.Time
+		// readers need an IANA zone name, abbreviations such as EDT,
+		// unnamed zones and the machine's own Local zone are written
+		// in UTC instead
+		zone := t.Location().String()
+		if _, err := time.LoadLocation(zone); err != nil || zone == "Local" || zone == "" {
+			t, zone = t.UTC(), "UTC"
+		}
+		fmt.Fprintf(&b, "year = %d\nmonth = %d\nday = %d\n", t.Year(), t.Month(), t.Day())
+		fmt.Fprintf(&b, "time = %s\n", t.Format("15:04:05"))
+		fmt.Fprintf(&b, "time_zone = %q\n", zone)
+	}
+
+	_, err = w.Write(b.Bytes())
+	return err
+}
+
+// tomlValue is a value read from a PHH file, a string, int,
+// bool, bare value such as a time, or an array of values
+type tomlValue struct {
+	line  int
+	value interface{}
+}
+
+// tomlScanner reads the subset of TOML used by PHH files
+type tomlScanner struct {
+	s    string
+	pos  int
+	line int
+}
+
+func (t *tomlScanner) errorf(format string, args ...interface{}) error {
+	return fmt.Errorf("line %d: %s", t.line, fmt.Sprintf(format, args...))
+}
+
+func (t *tomlScanner) peek() byte {
+	if t.pos < len(t.s) {
+		return t.s[t.pos]
+	}
+	return 0
+}
+
+// skip moves past spaces and comments, and new lines if newlines is true
+func (t *tomlScanner) skip(newlines bool) {
+	for t.pos < len(t.s) {
+		switch c := t.s[t.pos]; {
+		case c == ' ' || c == '\t' || c == '\r':
+			t.pos++
+		case c == '#':
+			for t.pos < len(t.s) && t.s[t.pos] != '\n' {
+				t.pos++
+			}
+		case c == '\n' && newlines:
+			t.pos++
+			t.line++
+		default:
+			return
+		}
+	}
+}
+
+func (t *tomlScanner) value() (*tomlValue, error) {
+	v := &tomlValue{line: t.line}
+	switch c := t.peek(); c {
+	case '[':
+		t.pos++
+		var items []*tomlValue
+		for {
+			t.skip(true)
+			if t.peek() == ']' {
+				t.pos++
+				v.value = items
+				return v, nil
+			}
+			item, err := t.value()
+			if err != nil {
+				return nil, err
+			}
+			items = append(items, item)
+			t.skip(true)
+			switch t.peek() {
+			case ',':
+				t.pos++
+			case ']':
+			default:
+				return nil, t.errorf("expected , or ] in array")
+			}
+		}
+	case '"', '\'':
+		end := t.pos + 1
+		for end < len(t.s) && t.s[end] != c && t.s[end] != '\n' {
+			if c == '"' && t.s[end] == '\\' {
+				end++
+			}
+			end++
+		}
+		if end >= len(t.s) || t.s[end] != c {
+			return nil, t.errorf("unterminated string")
+		}
+		raw := t.s[t.pos : end+1]
+		t.pos = end + 1
+		if c == '\'' {
+			v.value = raw[1 : len(raw)-1]
+			return v, nil
+		}
+		s, err := strconv.Unquote(raw)
+		if err != nil {
+			return nil, t.errorf("malformed string %s", raw)
+		}
+		v.value = s
+		return v, nil
+	}
+	start := t.pos
+	for t.pos < len(t.s) && !strings.ContainsRune(" \t\r\n,]#", rune(t.s[t.pos])) {
+		t.pos++
+	}
+	raw := t.s[start:t.pos]
+	switch {
+	case raw == "":
+		return nil, t.errorf("missing value")
+	case raw == "true" || raw == "false":
+		v.value = raw == "true"
+	default:
+		if n, err := strconv.Atoi(strings.ReplaceAll(raw, "_", "")); err == nil {
+			v.value = n
+		} else {
+			v.value = raw
+		}
+	}
+	return v, nil
+}
+
+// tomlTable is the key value pairs of one hand, line is where
+// its table header, or else its first key, is
+type tomlTable struct {
+	line int
+	keys map[string]*tomlValue
+}
+
+// parseTOML reads key value pairs, a table header such as [1]
+// starts a new hand as used by files holding many hands
+func parseTOML(s string) ([]*tomlTable, error) {
+	t := &tomlScanner{s: s, line: 1}
+	var tables []*tomlTable
+	var table *tomlTable
+	header := 0
+	for {
+		t.skip(true)
+		if t.pos >= len(t.s) {
+			break
+		}
+		if t.peek() == '[' {
+			end := strings.IndexByte(t.s[t.pos:], '\n')
+			if end == -1 {
+				end = len(t.s) - t.pos
+			}
+			if !strings.HasSuffix(strings.TrimSpace(t.s[t.pos:t.pos+end]), "]") {
+				return nil, t.errorf("malformed table header")
+			}
+			t.pos += end
+			table = nil
+			header = t.line
+			continue
+		}
+		start := t.pos
+		for t.pos < len(t.s) && (t.s[t.pos] == '_' || t.s[t.pos] == '-' ||
+			unicode.IsLetter(rune(t.s[t.pos])) || unicode.IsDigit(rune(t.s[t.pos]))) {
+			t.pos++
+		}
+		key := t.s[start:t.pos]
+		t.skip(false)
+		if key == "" || t.peek() != '=' {
+			return nil, t.errorf("expected key = value")
+		}
+		t.pos++
+		t.skip(false)
+		v, err := t.value()
+		if err != nil {
+			return nil, err
+		}
+		t.skip(false)
+		if c := t.peek(); c != '\n' && c != 0 {
+			return nil, t.errorf("unexpected %q after value of %s", c, key)
+		}
+		if table == nil {
+			table = &tomlTable{line: v.line, keys: make(map[string]*tomlValue)}
+			if header > 0 {
+				table.line = header
+			}
+			tables = append(tables, table)
+			header = 0
+		}
+		if _, ok := table.keys[key]; ok {
+			return nil, t.errorf("duplicate key %s", key)
+		}
+		table.keys[key] = v
+	}
+	return tables, nil
+}
+
+// phhVariants are the PHH variants dealt like Texas hold'em, with two
+// hole cards each. Omaha and stud hands cannot be held in a Record.
+var phhVariants = map[string]string{
+	"NT": "Hold'em No Limit",
+	"NS": "Short Deck Hold'em No Limit",
+	"FT": "Hold'em Fixed Limit",
+}
+
+// phhHand builds a Record from the keys of a PHH hand
+type phhHand struct {
+	// line is where the hand starts, for errors about missing keys
+	line int
+	keys map[string]*tomlValue
+	rec  *Record
+}
+
+func (h *phhHand) errorf(v *tomlValue, format string, args ...interface{}) error {
+	return fmt.Errorf("line %d: %s", v.line, fmt.Sprintf(format, args...))
+}
+
+// phhGet returns the value of key, checking it has the type T
+func phhGet[T any](h *phhHand, key string, required bool) (T, *tomlValue, error) {
+	var zero T
+	v, ok := h.keys[key]
+	if !ok {
+		if required {
+			return zero, nil, fmt.Errorf("line %d: missing %s", h.line, key)
+		}
+		return zero, nil, nil
+	}
+	t, ok := v.value.(T)
+	if !ok {
+		return zero, nil, h.errorf(v, "%s has the wrong type", key)
+	}
+	return t, v, nil
+}
+
+// ints returns an array of n ints
+func (h *phhHand) ints(key string, n int, required bool) ([]int, error) {
+	items, v, err := phhGet[[]*tomlValue](h, key, required)
+	if err != nil || v == nil {
+		return nil, err
+	}
+	if n >= 0 && len(items) != n {
+		return nil, h.errorf(v, "%s has %d values for %d players", key, len(items), n)
+	}
+	ints := make([]int, len(items))
+	for i, item := range items {
+		n, ok := item.value.(int)
+		if !ok {
+			return nil, h.errorf(item, "%s must hold integers", key)
+		}
+		ints[i] = n
+	}
+	return ints, nil
+}
+
+// ParsePHH reads hands in the Poker Hand History TOML format, either
+// a single hand or many as tables of a .phhs file. Only variants dealt
+// like Texas hold'em are supported. Errors give the line of the input.
+func ParsePHH(r io.Reader) ([]*Record, error) {
+	b, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	tables, err := parseTOML(string(b))
+	if err != nil {
+		return nil, err
+	}
+	var records []*Record
+	for _, table := range tables {
+		h := &phhHand{line: table.line, keys: table.keys, rec: &Record{}}
+		if err := h.parse(); err != nil {
+			return nil, err
+		}
+		records = append(records, h.rec)
+	}
+	return records, nil
+}
+
+func (h *phhHand) parse() error {
+	r := h.rec
+	variant, v, err := phhGet[string](h, "variant", true)
+	if err != nil {
+		return err
+	}
+	var ok bool
+	if r.Game, ok = phhVariants[variant]; !ok {
+		return h.errorf(v, "variant %s is not supported", variant)
+	}
+	stacks, err := h.ints("starting_stacks", -1, true)
+	if err != nil {
+		return err
+	}
+	n := len(stacks)
+	if n < 2 {
+		return h.errorf(h.keys["starting_stacks"], "a hand needs at least 2 players")
+	}
+	antes, err := h.ints("antes", n, true)
+	if err != nil {
+		return err
+	}
+	blinds, err := h.ints("blinds_or_straddles", n, true)
+	if err != nil {
+		return err
+	}
+	seats, err := h.ints("seats", n, false)
+	if err != nil {
+		return err
+	}
+	names, v, err := phhGet[[]*tomlValue](h, "players", false)
+	if err != nil {
+		return err
+	}
+	if v != nil && len(names) != n {
+		return h.errorf(v, "players has %d values for %d players", len(names), n)
+	}
+
+	// players are ordered from the seat after the button,
+	// heads up the big blind is first
+	r.Button = n - 1
+	r.SmallBlind, r.BigBlind = blinds[0], blinds[1]
+	if n == 2 {
+		r.SmallBlind, r.BigBlind = blinds[1], blinds[0]
+	}
+	left := make([]int, n)
+	for i := range stacks {
+		p := &Player{Name: fmt.Sprintf("p%d", i+1), Seat: i + 1, Stack: stacks[i]}
+		if names != nil {
+			if p.Name, ok = names[i].value.(string); !ok {
+				return h.errorf(names[i], "players must hold strings")
+			}
+		}
+		if seats != nil {
+			p.Seat = seats[i]
+		}
+		p.Ante = min(antes[i], p.Stack)
+		p.Blind = min(blinds[i], p.Stack-p.Ante)
+		left[i] = p.Stack - p.Ante - p.Blind
+		r.Ante = max(r.Ante, antes[i])
+		r.Players = append(r.Players, p)
+	}
+
+	actions, v, err := phhGet[[]*tomlValue](h, "actions", true)
+	if err != nil {
+		return err
+	}
+	if err := h.actions(actions, blinds, left); err != nil {
+		return err
+	}
+	if err := h.results(left); err != nil {
+		return err
+	}
+	return h.info()
+}
+
+// actions replays the actions, left holds the chips
+// each player has behind and is updated as they bet
+func (h *phhHand) actions(actions []*tomlValue, blinds, left []int) error {
+	r := h.rec
+	n := len(r.Players)
+	bets := make([]int, n)
+	for i, p := range r.Players {
+		bets[i] = p.Blind
+	}
+	current := 0
+	for _, b := range blinds {
+		current = max(current, b)
+	}
+	street := game.Preflop
+	for _, v := range actions {
+		s, ok := v.value.(string)
+		if !ok {
+			return h.errorf(v, "actions must hold strings")
+		}
+		s, _, _ = strings.Cut(s, " #")
+		f := strings.Fields(s)
+		if len(f) < 2 {
+			return h.errorf(v, "malformed action %q", s)
+		}
+		if f[0] == "d" {
+			if len(f) < 3 {
+				return h.errorf(v, "malformed action %q", s)
+			}
+			switch f[1] {
+			case "dh":
+				i, err := phhPlayer(f[2], n)
+				if err != nil || len(f) != 4 {
+					return h.errorf(v, "malformed action %q", s)
+				}
+				// unknown cards are written as ??
+				if !strings.Contains(f[3], "?") {
+					if r.Players[i].Hole, err = hand.Parse(f[3]); err != nil {
+						return h.errorf(v, "%v", err)
+					}
+				}
+			case "db":
+				cards, err := hand.Parse(f[2])
+				if err != nil {
+					return h.errorf(v, "%v", err)
+				}
+				r.Board = append(r.Board, cards...)
+				if len(r.Board) < 3 || len(r.Board) > 5 || len(r.Board) != int(street)+3 {
+					return h.errorf(v, "wrong number of board cards")
+				}
+				h.returnUncalled(bets, left)
+				street++
+				bets = make([]int, n)
+				current = 0
+			default:
+				return h.errorf(v, "action %q is not supported", s)
+			}
+			continue
+		}
+
+		i, err := phhPlayer(f[0], n)
+		if err != nil {
+			return h.errorf(v, "malformed action %q", s)
+		}
+		a := game.Action{Seat: i, Street: street}
+		switch f[1] {
+		case "f":
+			a.Type = game.Fold
+		case "cc":
+			a.Type = game.Check
+			if current > bets[i] {
+				a.Type = game.Call
+				a.Amount = min(current-bets[i], left[i])
+			}
+			bets[i] += a.Amount
+		case "cbr":
+			if len(f) != 3 {
+				return h.errorf(v, "malformed action %q", s)
+			}
+			if a.Amount, err = strconv.Atoi(f[2]); err != nil || a.Amount-bets[i] > left[i] || a.Amount <= current {
+				return h.errorf(v, "invalid bet %q", s)
+			}
+			a.Type = game.Raise
+			if current == 0 {
+				a.Type = game.Bet
+			}
+			left[i] -= a.Amount - bets[i]
+			bets[i], current = a.Amount, a.Amount
+		case "sm":
+			if len(f) == 3 && !strings.Contains(f[2], "?") {
+				if r.Players[i].Hole, err = hand.Parse(f[2]); err != nil {
+					return h.errorf(v, "%v", err)
+				}
+				r.Players[i].Shown = true
+			}
+			continue
+		default:
+			return h.errorf(v, "action %q is not supported", s)
+		}
+		if a.Type == game.Call {
+			left[i] -= a.Amount
+		}
+		a.AllIn = left[i] == 0 && a.Type != game.Fold && a.Type != game.Check
+		r.Actions = append(r.Actions, a)
+	}
+	h.returnUncalled(bets, left)
+	return nil
+}
+
+// returnUncalled gives back the part of the largest bet on a street
+// that nobody called, PHH leaves this implicit
+func (h *phhHand) returnUncalled(bets, left []int) {
+	top, second := 0, 0
+	for i, b := range bets {
+		if b > bets[top] {
+			second = max(second, bets[top])
+			top = i
+		} else if i != top {
+			second = max(second, b)
+		}
+	}
+	if excess := bets[top] - second; excess > 0 {
+		h.rec.Players[top].Returned += excess
+		left[top] += excess
+	}
+}
+
+// phhPlayer reads a player such as p1, returning its index
+func phhPlayer(s string, n int) (int, error) {
+	i, err := strconv.Atoi(strings.TrimPrefix(s, "p"))
+	if err != nil || !strings.HasPrefix(s, "p") || i < 1 || i > n {
+		return 0, fmt.Errorf("%q is not a player", s)
+	}
+	return i - 1, nil
+}
+
+// results sets the winnings of each player from the winnings key or,
+// failing that, the difference between the finishing stacks and the
+// chips left behind after betting and uncalled bets
+func (h *phhHand) results(left []int) error {
+	n := len(h.rec.Players)
+	won, err := h.ints("winnings", n, false)
+	if err != nil {
+		return err
+	}
+	if won == nil {
+		finishing, err := h.ints("finishing_stacks", n, false)
+		if err != nil || finishing == nil {
+			return err
+		}
+		won = make([]int, n)
+		for i := range won {
+			won[i] = finishing[i] - left[i]
+		}
+	}
+	for i, p := range h.rec.Players {
+		p.Won = won[i]
+	}
+	return nil
+}
+
+// info reads the optional details of where and when the hand was played
+func (h *phhHand) info() error {
+	r := h.rec
+	id, _, err := phhGet[int](h, "hand", false)
+	if err != nil {
+		return err
+	}
+	r.ID = int64(id)
+	if r.Table, _, err = phhGet[string](h, "table", false); err != nil {
+		return err
+	}
+	if r.Currency, _, err = phhGet[string](h, "currency", false); err != nil {
+		return err
+	}
+	year, v, err := phhGet[int](h, "year", false)
+	if err != nil || v == nil {
+		return err
+	}
+	month, _, err := phhGet[int](h, "month", true)
+	if err != nil {
+		return err
+	}
+	day, _, err := phhGet[int](h, "day", true)
+	if err != nil {
+		return err
+	}
+	clock, v, err := phhGet[string](h, "time", false)
+	if err != nil {
+		return err
+	}
+	var hour, minute, second int
+	if v != nil {
+		if _, err := fmt.Sscanf(clock, "%d:%d:%d", &hour, &minute, &second); err != nil {
+			return h.errorf(v, "malformed time %q", clock)
+		}
+	}
+	r.Time = time.Date(year, time.Month(month), day, hour, minute, second, 0, time.UTC)
+	zone, v, err := phhGet[string](h, "time_zone", false)
+	if err != nil {
+		return err
+	}
+	// WritePHH writes IANA names, abbreviations such as EDT are also read
+	if v != nil && zone != "UTC" {
+		var ok bool
+		if r.Time, ok = inZone(r.Time, zone); !ok {
+			return h.errorf(v, "unknown time zone %q", zone)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,108 @@
+package history
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/aultimus/gosouth/game"
+	"github.com/aultimus/gosouth/hand"
+)
+
+// Record is a hand read from a hand history. Players are in clockwise
+// order and the Seat of each Action indexes Players. Amounts are in chips,
+// or in cents when the hand was played for a Currency.
+type Record struct {
+	ID         int64
+	Table      string
+	Time       time.Time
+	Game       string
+	Currency   string
+	SmallBlind int
+	BigBlind   int
+	Ante       int
+	Button     int
+	Players    []*Player
+	Actions    []game.Action
+	Board      hand.Hand
+}
+
+// Player is a player in a Record
+type Player struct {
+	Name string
+	Seat int
+	// Stack is the player's chips before the hand
+	Stack int
+	Ante  int
+	// Blind is the blind or straddle the player posted, if any
+	Blind int
+	// Hole holds the player's cards if they were dealt to
+	// the writer of the history or shown, nil otherwise
+	Hole  hand.Hand
+	Shown bool
+	// Returned is the part of a bet by the player that nobody called
+	Returned int
+	Won      int
+}
+
+// Player returns the index of the player with a name, -1 if there is none
+func (r *Record) Player(name string) int {
+	for i, p := range r.Players {
+		if p.Name == name {
+			return i
+		}
+	}
+	return -1
+}
+
+// Invested returns the chips a player put in the pot, antes and
+// blinds included, less any uncalled bet returned to them
+func (r *Record) Invested(player int) int {
+	p := r.Players[player]
+	total := p.Ante + p.Blind - p.Returned
+	bet := p.Blind
+	street := game.Preflop
+	for _, a := range r.Actions {
+		if a.Seat != player {
+			continue
+		}
+		if a.Street != street {
+			street, bet = a.Street, 0
+		}
+		switch a.Type {
+		case game.Call:
+			total += a.Amount
+			bet += a.Amount
+		case game.Bet, game.Raise:
+			total += a.Amount - bet
+			bet = a.Amount
+		}
+	}
+	return total
+}
+
+// Net returns the chips a player won or lost in the hand
+func (r *Record) Net(player int) int {
+	return r.Players[player].Won - r.Invested(player)
+}
+
+// Position names a player's position relative to the button: BTN, SB,
+// BB, then UTG and UTG+1... for early positions and LJ, HJ and CO for
+// the three seats before the button. Heads up the button is the small
+// blind and is named BTN.
+func (r *Record) Position(player int) string {
+	n := len(r.Players)
+	d := (player - r.Button + n) % n
+	switch {
+	case d == 0:
+		return "BTN"
+	case n == 2 || d == 2:
+		return "BB"
+	case d == 1:
+		return "SB"
+	case d == 3:
+		return "UTG"
+	case n-d <= 3:
+		return []string{"CO", "HJ", "LJ"}[n-d-1]
+	}
+	return fmt.Sprintf("UTG+%d", d-3)
+}
@@ -0,0 +1,569 @@
+package history
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/aultimus/gosouth/game"
+	"github.com/aultimus/gosouth/hand"
+)
+
+// TextTime is the layout of the time in a text hand history header,
+// times are written in UTC as zone abbreviations are ambiguous
+const TextTime = "2006/01/02 15:04:05 MST"
+
+// zoneOffsets are the offsets from UTC in hours of zone abbreviations
+// seen in hand histories, Go only knows those of the local zone
+var zoneOffsets = map[string]int{
+	"GMT": 0, "WET": 0, "BST": 1, "WEST": 1, "CET": 1, "CEST": 2, "EET": 2, "EEST": 3, "MSK": 3,
+	"EST": -5, "EDT": -4, "CST": -6, "CDT": -5, "MST": -7, "MDT": -6, "PST": -8, "PDT": -7,
+	"AEST": 10, "AEDT": 11,
+}
+
+// inZone returns the wall clock time of wall, read as UTC, in the zone
+// named by an IANA name or an abbreviation in zoneOffsets. ET, which
+// PokerStars writes, is the US Eastern zone. It returns false and wall
+// for a zone it does not know.
+func inZone(wall time.Time, zone string) (time.Time, bool) {
+	y, mo, d := wall.Date()
+	h, mi, sec := wall.Clock()
+	if zone == "ET" {
+		if loc, err := time.LoadLocation("America/New_York"); err == nil {
+			return time.Date(y, mo, d, h, mi, sec, 0, loc), true
+		}
+		// without a zone database follow the US daylight saving rules
+		zone = "EST"
+		if usDaylight(wall) {
+			zone = "EDT"
+		}
+	}
+	if hours, ok := zoneOffsets[zone]; ok {
+		return time.Date(y, mo, d, h, mi, sec, 0, time.FixedZone(zone, hours*60*60)), true
+	}
+	if zone != "" && zone != "Local" {
+		if loc, err := time.LoadLocation(zone); err == nil {
+			return time.Date(y, mo, d, h, mi, sec, 0, loc), true
+		}
+	}
+	return wall, false
+}
+
+// usDaylight returns true if the wall clock time, read as UTC, is in US
+// daylight saving time, from 2am on the second Sunday of March to 2am
+// on the first Sunday of November
+func usDaylight(wall time.Time) bool {
+	sunday := func(month time.Month, n int) time.Time {
+		first := time.Date(wall.Year(), month, 1, 2, 0, 0, 0, time.UTC)
+		return first.AddDate(0, 0, (7-int(first.Weekday()))%7+7*(n-1))
+	}
+	return !wall.Before(sunday(time.March, 2)) && wall.Before(sunday(time.November, 1))
+}
+
+// textTime reads a header time such as "2020/01/15 9:04:35 ET", it
+// returns false with the time read as UTC if the zone is not known
+func textTime(s string) (time.Time, bool, error) {
+	f := strings.Fields(s)
+	if len(f) < 2 || len(f) > 3 {
+		return time.Time{}, false, fmt.Errorf("malformed time %q", s)
+	}
+	wall, err := time.Parse("2006/01/02 15:04:05", f[0]+" "+f[1])
+	if err != nil {
+		return time.Time{}, false, fmt.Errorf("malformed time %q", s)
+	}
+	if len(f) == 2 || f[2] == "UTC" {
+		return wall, true, nil
+	}
+	t, ok := inZone(wall, f[2])
+	return t, ok, nil
+}
+
+// streetHeaders names each street after the deal, indexed by game.STREET
+var streetHeaders = []string{"HOLE CARDS", "FLOP", "TURN", "RIVER"}
+
+// cards writes cards separated by spaces
+func cards(h hand.Hand) string {
+	s := make([]string, len(h))
+	for i, c := range h {
+		s[i] = c.String()
+	}
+	return strings.Join(s, " ")
+}
+
+func allIn(b bool) string {
+	if b {
+		return " and is all-in"
+	}
+	return ""
+}
+
+// WriteText writes a completed hand in the text layout used by
+// PokerStars, every seat's hole cards are written
+func WriteText(w io.Writer, h *Hand) error {
+	if err := h.check(); err != nil {
+		return err
+	}
+	g := h.Game
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "PokerStars Hand #%d: Hold'em %s (%d/%d) - %s\n", h.ID,
+		g.Config.Structure.Name(), g.Config.SmallBlind, g.Config.BigBlind, h.Time.UTC().Format(TextTime))
+	fmt.Fprintf(&b, "Table '%s' %d-max Seat #%d is the button\n", h.Table, len(g.Seats), g.Button+1)
+	stacks := h.startingStacks()
+	for i := range g.Seats {
+		fmt.Fprintf(&b, "Seat %d: %s (%d in chips)\n", i+1, h.name(i), stacks[i])
+	}
+
+	antes, blinds := h.posts()
+	for i, ante := range antes {
+		if ante > 0 {
+			stacks[i] -= ante
+			fmt.Fprintf(&b, "%s: posts the ante %d%s\n", h.name(i), ante, allIn(stacks[i] == 0))
+		}
+	}
+	for _, blind := range []struct {
+		seat int
+		name string
+	}{{g.SmallBlindSeat(), "small"}, {g.BigBlindSeat(), "big"}} {
+		if amount := blinds[blind.seat]; amount > 0 {
+			stacks[blind.seat] -= amount
+			fmt.Fprintf(&b, "%s: posts %s blind %d%s\n", h.name(blind.seat), blind.name, amount,
+				allIn(stacks[blind.seat] == 0))
+		}
+	}
+
+	lastStreet := game.Preflop
+	if len(g.Actions) > 0 {
+		lastStreet = g.Actions[len(g.Actions)-1].Street
+	}
+	returned := h.returned()
+	for street := game.Preflop; street <= game.River; street++ {
+		switch street {
+		case game.Preflop:
+			fmt.Fprintf(&b, "*** %s ***\n", streetHeaders[street])
+			for i := range g.Seats {
+				seat := (g.Button + 1 + i) % len(g.Seats)
+				fmt.Fprintf(&b, "Dealt to %s [%s]\n", h.name(seat), cards(g.Seats[seat].Hole))
+			}
+		case game.Flop:
+			if len(g.Board) < 3 {
+				break
+			}
+			fmt.Fprintf(&b, "*** %s *** [%s]\n", streetHeaders[street], cards(g.Board[:3]))
+		default:
+			n := int(street) + 2
+			if len(g.Board) < n {
+				break
+			}
+			fmt.Fprintf(&b, "*** %s *** [%s] [%s]\n", streetHeaders[street],
+				cards(g.Board[:n-1]), g.Board[n-1])
+		}
+		writeActions(&b, h, street, blinds)
+		if street == lastStreet {
+			for i, r := range returned {
+				if r > 0 {
+					fmt.Fprintf(&b, "Uncalled bet (%d) returned to %s\n", r, h.name(i))
+				}
+			}
+		}
+	}
+
+	shown := h.shown()
+	if len(shown) > 0 {
+		b.WriteString("*** SHOW DOWN ***\n")
+		for _, seat := range shown {
+			fmt.Fprintf(&b, "%s: shows [%s] (%s)\n", h.name(seat), cards(g.Seats[seat].Hole), h.describe(seat))
+		}
+	}
+	for i, p := range g.Pots {
+		for j, w := range p.Winners {
+			fmt.Fprintf(&b, "%s collected %d from %s\n", h.name(w), p.Shares[j], potName(i, len(g.Pots)))
+		}
+	}
+
+	b.WriteString("*** SUMMARY ***\n")
+	total := 0
+	for _, p := range g.Pots {
+		total += p.Amount
+	}
+	fmt.Fprintf(&b, "Total pot %d", total)
+	if len(g.Pots) > 1 {
+		for i, p := range g.Pots {
+			name := potName(i, len(g.Pots))
+			fmt.Fprintf(&b, " %s %d.", strings.ToUpper(name[:1])+name[1:], p.Amount)
+		}
+	}
+	b.WriteString(" | Rake 0\n")
+	if len(g.Board) > 0 {
+		fmt.Fprintf(&b, "Board [%s]\n", cards(g.Board))
+	}
+	for i := range g.Seats {
+		fmt.Fprintf(&b, "Seat %d: %s%s %s\n", i+1, h.name(i), position(g, i), summary(h, i, len(shown) > 0))
+	}
+
+	_, err := w.Write(b.Bytes())
+	return err
+}
+
+// writeActions writes the actions made on a street
+func writeActions(b *bytes.Buffer, h *Hand, street game.STREET, blinds []int) {
+	g := h.Game
+	current := 0
+	if street == game.Preflop {
+		current = max(blinds[g.SmallBlindSeat()], blinds[g.BigBlindSeat()])
+	}
+	for _, a := range g.Actions {
+		if a.Street != street {
+			continue
+		}
+		name := h.name(a.Seat)
+		switch a.Type {
+		case game.Fold:
+			fmt.Fprintf(b, "%s: folds\n", name)
+		case game.Check:
+			fmt.Fprintf(b, "%s: checks\n", name)
+		case game.Call:
+			fmt.Fprintf(b, "%s: calls %d%s\n", name, a.Amount, allIn(a.AllIn))
+		case game.Bet:
+			current = a.Amount
+			fmt.Fprintf(b, "%s: bets %d%s\n", name, a.Amount, allIn(a.AllIn))
+		case game.Raise:
+			fmt.Fprintf(b, "%s: raises %d to %d%s\n", name, a.Amount-current, a.Amount, allIn(a.AllIn))
+			current = a.Amount
+		}
+	}
+}
+
+func potName(i, numPots int) string {
+	switch {
+	case numPots == 1:
+		return "pot"
+	case i == 0:
+		return "main pot"
+	}
+	return fmt.Sprintf("side pot-%d", i)
+}
+
+// position describes the seats with a role in the hand
+func position(g *game.Game, seat int) string {
+	var s string
+	if seat == g.Button {
+		s += " (button)"
+	}
+	if seat == g.SmallBlindSeat() {
+		s += " (small blind)"
+	}
+	if seat == g.BigBlindSeat() {
+		s += " (big blind)"
+	}
+	return s
+}
+
+// summary describes how the hand ended for a seat
+func summary(h *Hand, seat int, showdown bool) string {
+	g := h.Game
+	s := g.Seats[seat]
+	if s.Folded {
+		for _, a := range g.Actions {
+			if a.Seat != seat || a.Type != game.Fold {
+				continue
+			}
+			if a.Street == game.Preflop {
+				antes, _ := h.posts()
+				if s.Total == antes[seat] {
+					return "folded before Flop (didn't bet)"
+				}
+				return "folded before Flop"
+			}
+			return fmt.Sprintf("folded on the %s", a.Street)
+		}
+	}
+	won := g.Winnings[seat]
+	if !showdown {
+		return fmt.Sprintf("collected (%d)", won)
+	}
+	if won > 0 {
+		return fmt.Sprintf("showed [%s] and won (%d) with %s", cards(s.Hole), won, h.describe(seat))
+	}
+	return fmt.Sprintf("showed [%s] and lost with %s", cards(s.Hole), h.describe(seat))
+}
+
+var (
+	headerRe = regexp.MustCompile(`^PokerStars (?:Zoom )?Hand #(\d+):\s*(.*)\(([^()/]+)/([^()/ ]+)(?: [A-Z]{3})?\) - (.*)$`)
+	tableRe  = regexp.MustCompile(`^Table '(.*)' \d+-max (?:\(.*\) )?Seat #(\d+) is the button`)
+	seatRe   = regexp.MustCompile(`^Seat (\d+): (.+) \((\S+) in chips(?:, [^)]*)?\)`)
+	streetRe = regexp.MustCompile(`^\*\*\* ([A-Z ]+) \*\*\*(.*)$`)
+	cardsRe  = regexp.MustCompile(`\[([^\]]*)\]`)
+	dealtRe  = regexp.MustCompile(`^Dealt to (.+?) \[([^\]]+)\]`)
+	postRe   = regexp.MustCompile(`^posts (small blind|big blind|the ante|small & big blinds) (\S+)( and is all-in)?$`)
+	actionRe = regexp.MustCompile(`^(folds|checks|calls|bets|raises)(?: (\S+))?(?: to (\S+))?( and is all-in)?$`)
+	wonRe    = regexp.MustCompile(`^(.+) collected (\S+) from (?:main |side )?pot`)
+	uncallRe = regexp.MustCompile(`^Uncalled bet \((\S+)\) returned to (.+)$`)
+)
+
+var actionTypes = map[string]game.ACTION{
+	"folds":  game.Fold,
+	"checks": game.Check,
+	"calls":  game.Call,
+	"bets":   game.Bet,
+	"raises": game.Raise,
+}
+
+// textParser holds the state of ParseText between lines
+type textParser struct {
+	records []*Record
+	rec     *Record
+	button  int
+	street  game.STREET
+	summary bool
+}
+
+// ParseText reads hand histories in the text layout used by PokerStars.
+// A reader may hold many hands, lines that do not affect the record of a
+// hand, such as chat, are skipped. Errors give the line of the input.
+func ParseText(r io.Reader) ([]*Record, error) {
+	p := &textParser{}
+	sc := bufio.NewScanner(r)
+	for n := 1; sc.Scan(); n++ {
+		line := strings.TrimRight(sc.Text(), "\r ")
+		if n == 1 {
+			line = strings.TrimPrefix(line, "\uFEFF")
+		}
+		if err := p.parse(line); err != nil {
+			return nil, fmt.Errorf("line %d: %v", n, err)
+		}
+	}
+	if err := sc.Err(); err != nil {
+		return nil, err
+	}
+	return p.records, nil
+}
+
+func (p *textParser) parse(line string) error {
+	if strings.HasPrefix(line, "PokerStars ") {
+		return p.header(line)
+	}
+	if line == "" || p.summary {
+		return nil
+	}
+	if p.rec == nil {
+		return fmt.Errorf("expected a hand header, not %q", line)
+	}
+	r := p.rec
+	switch {
+	case strings.HasPrefix(line, "Table '"):
+		m := tableRe.FindStringSubmatch(line)
+		if m == nil {
+			return fmt.Errorf("malformed table %q", line)
+		}
+		r.Table = m[1]
+		p.button, _ = strconv.Atoi(m[2])
+		return nil
+	case strings.HasPrefix(line, "Seat ") && p.street == game.Preflop && len(r.Actions) == 0:
+		return p.seat(line)
+	case strings.HasPrefix(line, "*** "):
+		return p.section(line)
+	case strings.HasPrefix(line, "Dealt to "):
+		m := dealtRe.FindStringSubmatch(line)
+		if m == nil {
+			return fmt.Errorf("malformed deal %q", line)
+		}
+		i := r.Player(m[1])
+		if i == -1 {
+			return fmt.Errorf("cards dealt to unknown player %q", m[1])
+		}
+		h, err := hand.Parse(m[2])
+		r.Players[i].Hole = h
+		return err
+	}
+	if m := uncallRe.FindStringSubmatch(line); m != nil {
+		i := r.Player(m[2])
+		if i == -1 {
+			return fmt.Errorf("bet returned to unknown player %q", m[2])
+		}
+		returned, err := p.amount(m[1])
+		r.Players[i].Returned += returned
+		return err
+	}
+	if m := wonRe.FindStringSubmatch(line); m != nil {
+		if i := r.Player(m[1]); i != -1 {
+			won, err := p.amount(m[2])
+			r.Players[i].Won += won
+			return err
+		}
+	}
+	// the name is matched against the players as it may contain ": "
+	for i, pl := range r.Players {
+		if rest, ok := strings.CutPrefix(line, pl.Name+": "); ok {
+			if err := p.action(i, rest); err != nil {
+				return fmt.Errorf("%s: %v", pl.Name, err)
+			}
+			return nil
+		}
+	}
+	return nil
+}
+
+func (p *textParser) header(line string) error {
+	m := headerRe.FindStringSubmatch(line)
+	if m == nil {
+		return fmt.Errorf("malformed header %q", line)
+	}
+	r := &Record{Game: strings.TrimSpace(m[2])}
+	if strings.IndexAny(m[3], "0123456789") > 0 {
+		r.Currency = m[3][:strings.IndexAny(m[3], "0123456789")]
+	}
+	p.records = append(p.records, r)
+	p.rec, p.button, p.street, p.summary = r, 0, game.Preflop, false
+
+	var err error
+	if r.ID, err = strconv.ParseInt(m[1], 10, 64); err != nil {
+		return err
+	}
+	if r.SmallBlind, err = p.amount(m[3]); err != nil {
+		return err
+	}
+	if r.BigBlind, err = p.amount(m[4]); err != nil {
+		return err
+	}
+	// the time may be followed by the time in another zone, which is
+	// used if the first zone is not known. Unknown zones are read as UTC.
+	when, other, _ := strings.Cut(m[5], " [")
+	var ok bool
+	if r.Time, ok, err = textTime(when); err != nil || ok || other == "" {
+		return err
+	}
+	if t, ok, err := textTime(strings.TrimSuffix(other, "]")); err == nil && ok {
+		r.Time = t
+	}
+	return nil
+}
+
+func (p *textParser) seat(line string) error {
+	m := seatRe.FindStringSubmatch(line)
+	if m == nil {
+		return fmt.Errorf("malformed seat %q", line)
+	}
+	seat, _ := strconv.Atoi(m[1])
+	stack, err := p.amount(m[3])
+	if err != nil {
+		return err
+	}
+	r := p.rec
+	if seat == p.button {
+		r.Button = len(r.Players)
+	}
+	r.Players = append(r.Players, &Player{Name: m[2], Seat: seat, Stack: stack})
+	return nil
+}
+
+func (p *textParser) section(line string) error {
+	m := streetRe.FindStringSubmatch(line)
+	if m == nil {
+		return fmt.Errorf("malformed section %q", line)
+	}
+	var size int
+	switch m[1] {
+	case "HOLE CARDS", "SHOW DOWN":
+		return nil
+	case "SUMMARY":
+		p.summary = true
+		return nil
+	case "FLOP":
+		p.street, size = game.Flop, 3
+	case "TURN":
+		p.street, size = game.Turn, 1
+	case "RIVER":
+		p.street, size = game.River, 1
+	default:
+		return fmt.Errorf("%s is not supported", strings.ToLower(m[1]))
+	}
+	// the new cards are in the last brackets
+	groups := cardsRe.FindAllStringSubmatch(m[2], -1)
+	if len(groups) == 0 {
+		return fmt.Errorf("no cards for the %s", p.street)
+	}
+	h, err := hand.Parse(groups[len(groups)-1][1])
+	if err != nil {
+		return err
+	}
+	if len(h) != size || len(p.rec.Board)+size != int(p.street)+2 {
+		return fmt.Errorf("wrong number of cards for the %s", p.street)
+	}
+	p.rec.Board = append(p.rec.Board, h...)
+	return nil
+}
+
+// action reads what follows a player's name, anything
+// that is not a post, action or shown hand is skipped
+func (p *textParser) action(i int, rest string) error {
+	r := p.rec
+	pl := r.Players[i]
+	switch verb, _, _ := strings.Cut(rest, " "); verb {
+	case "posts":
+		m := postRe.FindStringSubmatch(rest)
+		if m == nil {
+			return fmt.Errorf("malformed post %q", rest)
+		}
+		amount, err := p.amount(m[2])
+		if m[1] == "the ante" {
+			pl.Ante += amount
+			r.Ante = max(r.Ante, amount)
+		} else {
+			pl.Blind += amount
+		}
+		return err
+	case "shows":
+		m := cardsRe.FindStringSubmatch(rest)
+		if m == nil {
+			return fmt.Errorf("malformed show %q", rest)
+		}
+		h, err := hand.Parse(m[1])
+		pl.Hole, pl.Shown = h, true
+		return err
+	case "folds", "checks", "calls", "bets", "raises":
+		m := actionRe.FindStringSubmatch(rest)
+		if m == nil {
+			return fmt.Errorf("malformed action %q", rest)
+		}
+		a := game.Action{Seat: i, Street: p.street, Type: actionTypes[m[1]], AllIn: m[4] != ""}
+		amount := m[2]
+		if a.Type == game.Raise {
+			amount = m[3]
+		}
+		if (a.Type == game.Fold || a.Type == game.Check) != (amount == "") ||
+			(a.Type == game.Raise) != (m[3] != "") || (a.Type == game.Raise && m[2] == "") {
+			return fmt.Errorf("malformed action %q", rest)
+		}
+		if amount != "" {
+			var err error
+			if a.Amount, err = p.amount(amount); err != nil {
+				return err
+			}
+		}
+		r.Actions = append(r.Actions, a)
+	}
+	return nil
+}
+
+// amount reads an amount of chips, or cents if the hand has a currency
+func (p *textParser) amount(s string) (int, error) {
+	if p.rec.Currency == "" {
+		n, err := strconv.Atoi(s)
+		if err != nil || n < 0 {
+			return 0, fmt.Errorf("%q is not an amount", s)
+		}
+		return n, nil
+	}
+	whole, frac, _ := strings.Cut(strings.TrimPrefix(s, p.rec.Currency), ".")
+	w, err := strconv.Atoi(whole)
+	f, ferr := strconv.Atoi((frac + "00")[:2])
+	if err != nil || ferr != nil || len(frac) > 2 || w < 0 {
+		return 0, fmt.Errorf("%q is not an amount", s)
+	}
+	return w*100 + f, nil
+}
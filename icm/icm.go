@@ -0,0 +1,227 @@
+package icm
+
+import (
+	"fmt"
+	"math/bits"
+
+	"github.com/aultimus/gosouth/deck"
+)
+
+// maxPlayers bounds the players with chips, the models
+// work over every subset of them
+const maxPlayers = 20
+
+// live returns the players with chips and their total chips,
+// players without chips are out and finish below them
+func live(stacks []int) ([]int, int, error) {
+	var players []int
+	total := 0
+	for i, s := range stacks {
+		if s < 0 {
+			return nil, 0, fmt.Errorf("player %d has a negative stack %d", i, s)
+		}
+		if s > 0 {
+			players = append(players, i)
+			total += s
+		}
+	}
+	if len(players) == 0 {
+		return nil, 0, fmt.Errorf("no player has chips")
+	}
+	if len(players) > maxPlayers {
+		return nil, 0, fmt.Errorf("%d players with chips is more than the %d supported", len(players), maxPlayers)
+	}
+	return players, total, nil
+}
+
+// busted shares the payouts for the places below the players
+// with chips equally between the players without
+func busted(stacks []int, payouts []float64, numLive int, eq []float64) {
+	numOut := len(stacks) - numLive
+	if numOut == 0 {
+		return
+	}
+	share := 0.0
+	for place := numLive; place < len(stacks) && place < len(payouts); place++ {
+		share += payouts[place]
+	}
+	for i, s := range stacks {
+		if s == 0 {
+			eq[i] = share / float64(numOut)
+		}
+	}
+}
+
+// ICM returns each player's expected prize under the Independent Chip
+// Model, where the chance of a player finishing in the next place is their
+// share of the chips of the players yet to be placed. payouts are the
+// prizes for each place, first place first.
+func ICM(stacks []int, payouts []float64) ([]float64, error) {
+	players, total, err := live(stacks)
+	if err != nil {
+		return nil, err
+	}
+	n := len(players)
+	eq := make([]float64, len(stacks))
+	places := min(n, len(payouts))
+
+	// p holds the chance the players in a set, a bitmask of players,
+	// took the places above the rest, chips holds the chips of the set
+	p := make([]float64, 1<<n)
+	chips := make([]int, 1<<n)
+	p[0] = 1
+	for set := 0; set < 1<<n; set++ {
+		if set > 0 {
+			low := bits.TrailingZeros(uint(set))
+			chips[set] = chips[set&^(1<<low)] + stacks[players[low]]
+		}
+		k := bits.OnesCount(uint(set))
+		if p[set] == 0 || k >= places {
+			continue
+		}
+		left := float64(total - chips[set])
+		for j, player := range players {
+			if set&(1<<j) != 0 {
+				continue
+			}
+			q := p[set] * float64(stacks[player]) / left
+			eq[player] += q * payouts[k]
+			p[set|1<<j] += q
+		}
+	}
+	busted(stacks, payouts, n, eq)
+	return eq, nil
+}
+
+// MalmuthWeitzman returns each player's expected prize under the
+// Malmuth-Weitzman model, where the chance of a player being the next
+// eliminated is inversely proportional to their stack
+func MalmuthWeitzman(stacks []int, payouts []float64) ([]float64, error) {
+	players, _, err := live(stacks)
+	if err != nil {
+		return nil, err
+	}
+	n := len(players)
+	eq := make([]float64, len(stacks))
+
+	// p holds the chance the players in a set are the ones left,
+	// subsets have lower bitmasks so are visited later
+	all := 1<<n - 1
+	p := make([]float64, 1<<n)
+	p[all] = 1
+	for set := all; set > 0; set-- {
+		if p[set] == 0 {
+			continue
+		}
+		k := bits.OnesCount(uint(set))
+		if k == 1 {
+			if len(payouts) > 0 {
+				eq[players[bits.TrailingZeros(uint(set))]] += p[set] * payouts[0]
+			}
+			continue
+		}
+		inverse := 0.0
+		for j, player := range players {
+			if set&(1<<j) != 0 {
+				inverse += 1 / float64(stacks[player])
+			}
+		}
+		for j, player := range players {
+			if set&(1<<j) == 0 {
+				continue
+			}
+			q := p[set] / float64(stacks[player]) / inverse
+			if k <= len(payouts) {
+				eq[player] += q * payouts[k-1]
+			}
+			p[set&^(1<<j)] += q
+		}
+	}
+	busted(stacks, payouts, n, eq)
+	return eq, nil
+}
+
+// FGS configures a future game simulation, which plays out hands ahead
+// with a simple model before valuing the stacks with ICM. Each hand either
+// the small blind folds to the big blind or, one time in Confrontation,
+// two random players get all in on a coin flip.
+type FGS struct {
+	SmallBlind int
+	// Button is the seat that had the button for the last hand played
+	Button        int
+	Hands         int
+	Trials        int
+	Confrontation int
+	Rand          deck.RNG
+}
+
+// Equity returns each player's expected prize averaged over the trials
+func (f FGS) Equity(stacks []int, payouts []float64) ([]float64, error) {
+	if _, _, err := live(stacks); err != nil {
+		return nil, err
+	}
+	if f.Trials <= 0 || f.Hands < 0 || f.Confrontation <= 0 || f.Rand == nil {
+		return nil, fmt.Errorf("invalid simulation %+v", f)
+	}
+	eq := make([]float64, len(stacks))
+	s := make([]int, len(stacks))
+	for t := 0; t < f.Trials; t++ {
+		copy(s, stacks)
+		f.play(s)
+		e, err := ICM(s, payouts)
+		if err != nil {
+			return nil, err
+		}
+		for i := range eq {
+			eq[i] += e[i] / float64(f.Trials)
+		}
+	}
+	return eq, nil
+}
+
+// play simulates the hands, stacks are updated in place
+func (f FGS) play(stacks []int) {
+	next := func(seat int) int {
+		for i := 1; i <= len(stacks); i++ {
+			if s := (seat + i) % len(stacks); stacks[s] > 0 {
+				return s
+			}
+		}
+		return seat
+	}
+	button := f.Button
+	for h := 0; h < f.Hands; h++ {
+		var players []int
+		for i, s := range stacks {
+			if s > 0 {
+				players = append(players, i)
+			}
+		}
+		if len(players) < 2 {
+			return
+		}
+		button = next(button)
+		sb, bb := next(button), next(next(button))
+		if len(players) == 2 {
+			sb, bb = button, next(button)
+		}
+		if f.Rand.Intn(f.Confrontation) != 0 {
+			won := min(f.SmallBlind, stacks[sb])
+			stacks[sb] -= won
+			stacks[bb] += won
+			continue
+		}
+		i := f.Rand.Intn(len(players))
+		j := f.Rand.Intn(len(players) - 1)
+		if j >= i {
+			j++
+		}
+		winner, loser := players[i], players[j]
+		if f.Rand.Intn(2) == 0 {
+			winner, loser = loser, winner
+		}
+		amount := min(stacks[winner], stacks[loser])
+		stacks[winner] += amount
+		stacks[loser] -= amount
+	}
+}
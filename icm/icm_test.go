@@ -0,0 +1,249 @@
+package icm
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/aultimus/gosouth/deck"
+	"github.com/aultimus/gosouth/hand"
+	"github.com/stretchr/testify/assert"
+)
+
+var payouts = []float64{50, 30, 20}
+
+func sum(f []float64) float64 {
+	total := 0.0
+	for _, v := range f {
+		total += v
+	}
+	return total
+}
+
+func TestICM(t *testing.T) {
+	a := assert.New(t)
+	eq, err := ICM([]int{50, 30, 20}, payouts)
+	a.NoError(err)
+	// first 0.5, second 0.3*0.5/0.7 + 0.2*0.5/0.8, third the rest
+	second := 0.3*0.5/0.7 + 0.2*0.5/0.8
+	a.InDelta(25+30*second+20*(0.5-second), eq[0], 1e-9)
+	a.InDelta(100, sum(eq), 1e-9)
+	a.True(eq[0] > eq[1] && eq[1] > eq[2])
+
+	eq, err = ICM([]int{10, 10, 10, 10}, payouts)
+	a.NoError(err)
+	for _, e := range eq {
+		a.InDelta(25, e, 1e-9)
+	}
+
+	// heads up equity is proportional to chips
+	eq, err = ICM([]int{75, 25}, []float64{100})
+	a.NoError(err)
+	a.InDeltaSlice([]float64{75, 25}, eq, 1e-9)
+
+	// players without chips share the places below the rest
+	eq, err = ICM([]int{100, 0, 0}, payouts)
+	a.NoError(err)
+	a.InDeltaSlice([]float64{50, 25, 25}, eq, 1e-9)
+
+	// a nine handed final table
+	stacks := []int{4000, 3500, 3000, 2500, 2000, 1500, 1000, 800, 200}
+	nine := []float64{40, 25, 15, 8, 5, 3, 2, 1, 1}
+	eq, err = ICM(stacks, nine)
+	a.NoError(err)
+	a.InDelta(100, sum(eq), 1e-9)
+	for i := 1; i < len(eq); i++ {
+		a.True(eq[i-1] > eq[i])
+	}
+	// the chip leader is worth less than their share of chips
+	a.True(eq[0] < 100*4000.0/18500)
+
+	_, err = ICM([]int{10, -1}, payouts)
+	a.Error(err)
+	_, err = ICM([]int{0, 0}, payouts)
+	a.Error(err)
+	_, err = ICM(make([]int, 21), payouts)
+	a.Error(err)
+}
+
+func TestMalmuthWeitzman(t *testing.T) {
+	a := assert.New(t)
+	// heads up the short stack busts in proportion to the big stack
+	eq, err := MalmuthWeitzman([]int{75, 25}, []float64{100})
+	a.NoError(err)
+	a.InDeltaSlice([]float64{75, 25}, eq, 1e-9)
+
+	eq, err = MalmuthWeitzman([]int{50, 30, 20}, payouts)
+	a.NoError(err)
+	// each player busts next in proportion to one over their stack
+	inverse := 1.0/50 + 1.0/30 + 1.0/20
+	shortOut, midOut, bigOut := (1.0/20)/inverse, (1.0/30)/inverse, (1.0/50)/inverse
+	short := 20*shortOut + midOut*(50*20/70.0+30*50/70.0) + bigOut*(50*0.4+30*0.6)
+	a.InDelta(short, eq[2], 1e-9)
+	a.InDelta(100, sum(eq), 1e-9)
+	a.True(eq[0] > eq[1] && eq[1] > eq[2])
+
+	eq, err = MalmuthWeitzman([]int{100, 0, 0}, payouts)
+	a.NoError(err)
+	a.InDeltaSlice([]float64{50, 25, 25}, eq, 1e-9)
+	_, err = MalmuthWeitzman([]int{-1, 10}, payouts)
+	a.Error(err)
+}
+
+func TestFGS(t *testing.T) {
+	a := assert.New(t)
+	stacks := []int{50, 30, 20}
+	f := FGS{SmallBlind: 1, Hands: 0, Trials: 10, Confrontation: 5, Rand: rand.New(rand.NewSource(1))}
+	eq, err := f.Equity(stacks, payouts)
+	a.NoError(err)
+	icm, _ := ICM(stacks, payouts)
+	a.InDeltaSlice(icm, eq, 1e-9)
+
+	f.Hands, f.Trials = 20, 2000
+	eq, err = f.Equity(stacks, payouts)
+	a.NoError(err)
+	a.InDelta(100, sum(eq), 1e-9)
+	a.Equal([]int{50, 30, 20}, stacks)
+
+	// the same seed plays the same hands
+	f.Rand = rand.New(rand.NewSource(1))
+	again, err := f.Equity(stacks, payouts)
+	a.NoError(err)
+	f.Rand = rand.New(rand.NewSource(1))
+	eq, err = f.Equity(stacks, payouts)
+	a.NoError(err)
+	a.Equal(eq, again)
+
+	f.Rand = nil
+	_, err = f.Equity(stacks, payouts)
+	a.Error(err)
+}
+
+func TestPushFold(t *testing.T) {
+	a := assert.New(t)
+	p := PushFold{
+		Stacks:     []int{40, 30, 27},
+		Payouts:    payouts,
+		Pot:        3,
+		Pusher:     2,
+		Caller:     0,
+		CallChance: 0.5,
+		Equity:     equity(50),
+	}
+	r, err := p.Evaluate()
+	a.NoError(err)
+	fold, _ := ICM([]int{43, 30, 27}, payouts)
+	stolen, _ := ICM([]int{40, 30, 30}, payouts)
+	win, _ := ICM([]int{13, 30, 57}, payouts)
+	lose, _ := ICM([]int{70, 30, 0}, payouts)
+	a.InDelta(fold[2], r.Fold, 1e-9)
+	a.InDelta(0.5*stolen[2]+0.25*win[2]+0.25*lose[2], r.Push, 1e-9)
+	a.Equal(50.0, r.Equity)
+
+	// calling a coin flip for everything is too costly on the bubble
+	p.CallChance = 1
+	r, err = p.Evaluate()
+	a.NoError(err)
+	a.False(r.Shove())
+	// nobody calling makes the steal free
+	p.CallChance = 0
+	r, err = p.Evaluate()
+	a.NoError(err)
+	a.True(r.Shove())
+
+	// drawing dead loses the whole shove when called
+	p.CallChance, p.Equity = 0.5, equity(0)
+	r, err = p.Evaluate()
+	a.NoError(err)
+	a.Equal(0.0, r.Equity)
+	a.InDelta(0.5*stolen[2]+0.5*lose[2], r.Push, 1e-9)
+
+	p.Caller = 2
+	_, err = p.Evaluate()
+	a.Error(err)
+	p.Caller, p.CallChance = 0, 2
+	_, err = p.Evaluate()
+	a.Error(err)
+	p.CallChance, p.Equity = 1, equity(101)
+	_, err = p.Evaluate()
+	a.Error(err)
+	// without an equity both hands are needed to work it out
+	p.Equity = nil
+	_, err = p.Evaluate()
+	a.Error(err)
+}
+
+func equity(e float64) *float64 {
+	return &e
+}
+
+// Long running test
+func TestPushFoldEquity(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping TestPushFoldEquity as in short mode")
+	}
+	a := assert.New(t)
+	hole, err := hand.Parse("AS AD")
+	a.NoError(err)
+	caller, err := hand.Parse("7C 2H")
+	a.NoError(err)
+	p := PushFold{
+		Stacks:     []int{40, 30, 27},
+		Payouts:    payouts,
+		Pot:        3,
+		Pusher:     2,
+		Caller:     0,
+		CallChance: 1,
+		Hole:       hole,
+		CallerHole: caller,
+	}
+	r, err := p.Evaluate()
+	a.NoError(err)
+	a.True(r.Equity > 85)
+	a.True(r.Shove())
+}
+
+// Long running test
+func TestPushFoldChop(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping TestPushFoldChop as in short mode")
+	}
+	a := assert.New(t)
+	hole, err := hand.Parse("AS KS")
+	a.NoError(err)
+	caller, err := hand.Parse("AD KC")
+	a.NoError(err)
+
+	// count the wins and chops of every board
+	used, err := append(append(hand.Hand(nil), hole...), caller...).Set()
+	a.NoError(err)
+	var win, tie, n float64
+	boards := deck.NewCombinations(deck.Without(deck.New(), used), 5)
+	for boards.Next() {
+		b := hand.Hand(boards.Comb())
+		winners := hand.Showdown([]hand.Hand{
+			append(append(hand.Hand(nil), b...), hole...),
+			append(append(hand.Hand(nil), b...), caller...),
+		})
+		n++
+		if len(winners) == 2 {
+			tie++
+		} else if winners[0] == 0 {
+			win++
+		}
+	}
+
+	p := PushFold{
+		Stacks:     []int{40, 30, 27},
+		Payouts:    payouts,
+		Pot:        3,
+		Pusher:     2,
+		Caller:     0,
+		CallChance: 1,
+		Hole:       hole,
+		CallerHole: caller,
+	}
+	r, err := p.Evaluate()
+	a.NoError(err)
+	// a chop is half the pot
+	a.InDelta((win+tie/2)/n*100, r.Equity, 1e-9)
+}
@@ -0,0 +1,104 @@
+package icm
+
+import (
+	"fmt"
+
+	"github.com/aultimus/gosouth/hand"
+	"github.com/aultimus/gosouth/headsup"
+)
+
+// PushFold is a spot where a player may shove all in or fold with a single
+// player left to act behind them, as when shoving into the big blind
+type PushFold struct {
+	// Stacks are the chips behind each player after blinds and antes
+	Stacks  []int
+	Payouts []float64
+	// Pot holds the blinds and antes, won by the caller when the pusher folds
+	Pot    int
+	Pusher int
+	Caller int
+	// CallChance is the chance the caller calls the shove
+	CallChance float64
+	// Equity is the pusher's percentage equity when called, a chop counting
+	// as half the pot. If nil it is worked out from Hole and CallerHole by dealing out every board, which
+	// takes seconds. Give Equity when building push/fold tables.
+	Equity     *float64
+	Hole       hand.Hand
+	CallerHole hand.Hand
+}
+
+// Result holds the pusher's expected prize for each decision
+type Result struct {
+	Push float64
+	Fold float64
+	// Equity is the pusher's percentage equity when called
+	Equity float64
+}
+
+// Shove returns true if pushing is worth more than folding
+func (r *Result) Shove() bool {
+	return r.Push > r.Fold
+}
+
+// Evaluate values pushing and folding with ICM
+func (p PushFold) Evaluate() (*Result, error) {
+	n := len(p.Stacks)
+	if p.Pusher < 0 || p.Pusher >= n || p.Caller < 0 || p.Caller >= n || p.Pusher == p.Caller {
+		return nil, fmt.Errorf("pusher %d and caller %d must be different players", p.Pusher, p.Caller)
+	}
+	if p.CallChance < 0 || p.CallChance > 1 {
+		return nil, fmt.Errorf("call chance %v is not a probability", p.CallChance)
+	}
+	if p.Stacks[p.Pusher] <= 0 {
+		return nil, fmt.Errorf("pusher %d has no chips", p.Pusher)
+	}
+	r := &Result{}
+	if p.Equity != nil {
+		if *p.Equity < 0 || *p.Equity > 100 {
+			return nil, fmt.Errorf("equity %v is not a percentage", *p.Equity)
+		}
+		r.Equity = *p.Equity
+	} else {
+		if p.Hole == nil || p.CallerHole == nil {
+			return nil, fmt.Errorf("give the equity or both players' hole cards")
+		}
+		res, err := headsup.Equity(p.Hole, p.CallerHole)
+		if err != nil {
+			return nil, err
+		}
+		r.Equity = res.Win[0]
+	}
+
+	// value returns the pusher's expected prize once the pusher
+	// has won chips from the caller
+	value := func(won int) (float64, error) {
+		s := append([]int(nil), p.Stacks...)
+		s[p.Pusher] += won
+		s[p.Caller] += p.Pot - won
+		eq, err := ICM(s, p.Payouts)
+		if err != nil {
+			return 0, err
+		}
+		return eq[p.Pusher], nil
+	}
+	var err error
+	if r.Fold, err = value(0); err != nil {
+		return nil, err
+	}
+	stolen, err := value(p.Pot)
+	if err != nil {
+		return nil, err
+	}
+	allIn := min(p.Stacks[p.Pusher], p.Stacks[p.Caller])
+	win, err := value(p.Pot + allIn)
+	if err != nil {
+		return nil, err
+	}
+	lose, err := value(-allIn)
+	if err != nil {
+		return nil, err
+	}
+	called := r.Equity/100*win + (1-r.Equity/100)*lose
+	r.Push = (1-p.CallChance)*stolen + p.CallChance*called
+	return r, nil
+}
@@ -0,0 +1,283 @@
+package mental
+
+import (
+	crand "crypto/rand"
+	"fmt"
+	"math/big"
+
+	"github.com/aultimus/gosouth/card"
+	"github.com/aultimus/gosouth/deck"
+	"github.com/aultimus/gosouth/hand"
+)
+
+var (
+	one = big.NewInt(1)
+	two = big.NewInt(2)
+)
+
+// Group is the prime shared by the peers, every key works modulo P.
+// SRA encryption is commutative: a card locked by several peers can
+// be unlocked by them in any order. P is a safe prime, 2q+1 for a prime
+// q, so that no card lies in a small subgroup a lock could not hide it in.
+type Group struct {
+	P *big.Int
+	// cards maps the encoding of each card back to it
+	cards map[string]*card.Card
+	order deck.Deck
+}
+
+// NewGroup returns a group over a random safe prime of the given bits,
+// finding one takes seconds from 512 bits
+func NewGroup(bits int) (*Group, error) {
+	if bits < 64 {
+		return nil, fmt.Errorf("a %d bit prime is too small", bits)
+	}
+	for {
+		q, err := crand.Prime(crand.Reader, bits-1)
+		if err != nil {
+			return nil, err
+		}
+		p := new(big.Int).Lsh(q, 1)
+		if p.Add(p, one).ProbablyPrime(32) {
+			return NewGroupPrime(p)
+		}
+	}
+}
+
+// NewGroupPrime returns a group over a safe prime agreed by the peers
+func NewGroupPrime(p *big.Int) (*Group, error) {
+	q := new(big.Int).Rsh(p, 1)
+	if p.BitLen() < 64 || !p.ProbablyPrime(32) || !q.ProbablyPrime(32) {
+		return nil, fmt.Errorf("%s is not a large safe prime", p)
+	}
+	g := &Group{P: p, cards: make(map[string]*card.Card), order: deck.New()}
+	for i, c := range g.order {
+		g.cards[g.encode(i).String()] = c
+	}
+	return g, nil
+}
+
+// encode maps the i'th card of a fresh deck to a quadratic residue,
+// encrypting keeps residues residues so every card looks alike
+func (g *Group) encode(i int) *big.Int {
+	m := big.NewInt(int64(i + 2))
+	return m.Mul(m, m).Mod(m, g.P)
+}
+
+// decode maps an unlocked value to its card
+func (g *Group) decode(m *big.Int) (*card.Card, error) {
+	c, ok := g.cards[m.String()]
+	if !ok {
+		return nil, fmt.Errorf("value is not a card, it is still locked or was tampered with")
+	}
+	return c, nil
+}
+
+// key is an SRA key pair, locking raises to E and unlocking to D
+type key struct {
+	E *big.Int
+	D *big.Int
+}
+
+// newKey picks E coprime with P-1 and its inverse D
+func (g *Group) newKey() (*key, error) {
+	phi := new(big.Int).Sub(g.P, one)
+	for {
+		e, err := crand.Int(crand.Reader, phi)
+		if err != nil {
+			return nil, err
+		}
+		if e.Cmp(two) <= 0 {
+			continue
+		}
+		if d := new(big.Int).ModInverse(e, phi); d != nil {
+			return &key{E: e, D: d}, nil
+		}
+	}
+}
+
+func (g *Group) lock(m *big.Int, k *key) *big.Int {
+	return new(big.Int).Exp(m, k.E, g.P)
+}
+
+func (g *Group) unlock(m *big.Int, k *key) *big.Int {
+	return new(big.Int).Exp(m, k.D, g.P)
+}
+
+// Peer is a player taking part in the deal. Its keys, and the
+// cards dealt to it, are never seen by the other peers.
+type Peer struct {
+	Name string
+	// RNG shuffles the deck, deck.CryptoRNG if nil
+	RNG deck.RNG
+
+	group *Group
+	key   *key
+	// cardKeys holds a key for each position in the deck
+	cardKeys []*key
+	hole     hand.Hand
+}
+
+// Hole returns the cards dealt to the peer
+func (p *Peer) Hole() hand.Hand {
+	return p.hole
+}
+
+func (p *Peer) rng() deck.RNG {
+	if p.RNG == nil {
+		return deck.CryptoRNG
+	}
+	return p.RNG
+}
+
+// lockAll locks every card with the peer's key and shuffles the deck
+func (p *Peer) lockAll(d []*big.Int) ([]*big.Int, error) {
+	var err error
+	if p.key, err = p.group.newKey(); err != nil {
+		return nil, err
+	}
+	out := make([]*big.Int, len(d))
+	for i, m := range d {
+		out[i] = p.group.lock(m, p.key)
+	}
+	rng := p.rng()
+	for i := 0; i < len(out)-1; i++ {
+		j := i + rng.Intn(len(out)-i)
+		out[i], out[j] = out[j], out[i]
+	}
+	return out, nil
+}
+
+// relock swaps the peer's key on every card for a key for each
+// position, so single cards can be unlocked without giving away the rest
+func (p *Peer) relock(d []*big.Int) ([]*big.Int, error) {
+	out := make([]*big.Int, len(d))
+	p.cardKeys = make([]*key, len(d))
+	for i, m := range d {
+		k, err := p.group.newKey()
+		if err != nil {
+			return nil, err
+		}
+		p.cardKeys[i] = k
+		out[i] = p.group.lock(p.group.unlock(m, p.key), k)
+	}
+	p.key = nil
+	return out, nil
+}
+
+// unlockKey gives away the key for a position, the other peers only
+// ever learn the keys of the cards dealt to them or revealed
+func (p *Peer) unlockKey(pos int) *key {
+	return p.cardKeys[pos]
+}
+
+// receive unlocks a card dealt to the peer with the keys
+// of the other peers and its own
+func (p *Peer) receive(m *big.Int, pos int, keys []*key) (*card.Card, error) {
+	for _, k := range append(keys, p.cardKeys[pos]) {
+		m = p.group.unlock(m, k)
+	}
+	c, err := p.group.decode(m)
+	if err != nil {
+		return nil, err
+	}
+	p.hole = append(p.hole, c)
+	return c, nil
+}
+
+// Table runs the deal between the peers, it only holds locked cards
+type Table struct {
+	Group *Group
+	Peers []*Peer
+	// Deck is the locked deck, public to every peer
+	Deck []*big.Int
+	next int
+}
+
+// NewTable seats the peers in a group
+func NewTable(g *Group, peers ...*Peer) (*Table, error) {
+	if len(peers) < 2 {
+		return nil, fmt.Errorf("a deal needs at least 2 peers, not %d", len(peers))
+	}
+	for _, p := range peers {
+		p.group = g
+		p.hole = nil
+	}
+	return &Table{Group: g, Peers: peers}, nil
+}
+
+// Shuffle has every peer in turn lock and shuffle the deck, then
+// swap its lock for a lock on each card. No peer knows the final order.
+func (t *Table) Shuffle() error {
+	d := make([]*big.Int, len(t.Group.order))
+	for i := range d {
+		d[i] = t.Group.encode(i)
+	}
+	var err error
+	for _, p := range t.Peers {
+		if d, err = p.lockAll(d); err != nil {
+			return fmt.Errorf("%s: %s", p.Name, err)
+		}
+	}
+	for _, p := range t.Peers {
+		if d, err = p.relock(d); err != nil {
+			return fmt.Errorf("%s: %s", p.Name, err)
+		}
+	}
+	t.Deck = d
+	t.next = 0
+	for _, p := range t.Peers {
+		p.hole = nil
+	}
+	return nil
+}
+
+// Remaining returns the number of cards left to deal
+func (t *Table) Remaining() int {
+	return len(t.Deck) - t.next
+}
+
+func (t *Table) take() (int, error) {
+	if t.Deck == nil {
+		return 0, fmt.Errorf("the deck has not been shuffled")
+	}
+	if t.Remaining() == 0 {
+		return 0, fmt.Errorf("the deck is empty")
+	}
+	t.next++
+	return t.next - 1, nil
+}
+
+// Deal deals the next card to a peer, the other peers hand it their
+// keys for the card so only it learns what the card is
+func (t *Table) Deal(to int) error {
+	if to < 0 || to >= len(t.Peers) {
+		return fmt.Errorf("peer %d is not at the table", to)
+	}
+	pos, err := t.take()
+	if err != nil {
+		return err
+	}
+	var keys []*key
+	for i, p := range t.Peers {
+		if i != to {
+			keys = append(keys, p.unlockKey(pos))
+		}
+	}
+	_, err = t.Peers[to].receive(t.Deck[pos], pos, keys)
+	return err
+}
+
+// Reveal turns over the next card for everyone, every peer
+// publishes its key for the card
+func (t *Table) Reveal() (*card.Card, error) {
+	pos, err := t.take()
+	if err != nil {
+		return nil, err
+	}
+	m := t.Deck[pos]
+	for _, p := range t.Peers {
+		m = t.Group.unlock(m, p.unlockKey(pos))
+	}
+	return t.Group.decode(m)
+}
@@ -0,0 +1,144 @@
+package mental
+
+import (
+	"math/big"
+	"math/rand"
+	"testing"
+
+	"github.com/aultimus/gosouth/card"
+	"github.com/stretchr/testify/assert"
+)
+
+// prime is a 128 bit safe prime, small enough to keep tests quick
+var prime, _ = new(big.Int).SetString("306662475637322999428899128374620821327", 10)
+
+func newTable(a *assert.Assertions, names ...string) *Table {
+	g, err := NewGroupPrime(prime)
+	a.NoError(err)
+	var peers []*Peer
+	for _, n := range names {
+		peers = append(peers, &Peer{Name: n})
+	}
+	tbl, err := NewTable(g, peers...)
+	a.NoError(err)
+	return tbl
+}
+
+func TestDeal(t *testing.T) {
+	a := assert.New(t)
+	tbl := newTable(a, "ann", "bob", "cat")
+	a.Error(tbl.Deal(0))
+	a.NoError(tbl.Shuffle())
+	a.Equal(52, tbl.Remaining())
+
+	// the locked deck shows no card
+	for _, m := range tbl.Deck {
+		_, err := tbl.Group.decode(m)
+		a.Error(err)
+	}
+
+	for r := 0; r < 2; r++ {
+		for i := range tbl.Peers {
+			a.NoError(tbl.Deal(i))
+		}
+	}
+	seen := make(map[string]bool)
+	for _, p := range tbl.Peers {
+		a.Equal(2, len(p.Hole()))
+		for _, c := range p.Hole() {
+			a.False(seen[c.String()], "%s dealt twice", c)
+			seen[c.String()] = true
+		}
+	}
+	for i := 0; i < 5; i++ {
+		c, err := tbl.Reveal()
+		a.NoError(err)
+		a.False(seen[c.String()], "%s dealt twice", c)
+		seen[c.String()] = true
+	}
+	a.Equal(41, tbl.Remaining())
+
+	for tbl.Remaining() > 0 {
+		c, err := tbl.Reveal()
+		a.NoError(err)
+		seen[c.String()] = true
+	}
+	a.Equal(52, len(seen))
+	_, err := tbl.Reveal()
+	a.Error(err)
+	a.Error(tbl.Deal(5))
+}
+
+func TestPrivateCards(t *testing.T) {
+	a := assert.New(t)
+	tbl := newTable(a, "ann", "bob")
+	a.NoError(tbl.Shuffle())
+	a.NoError(tbl.Deal(0))
+
+	// bob's key for the card alone does not unlock it
+	bob := tbl.Peers[1]
+	_, err := tbl.Group.decode(tbl.Group.unlock(tbl.Deck[0], bob.unlockKey(0)))
+	a.Error(err)
+	// nor do the keys for other cards
+	m := tbl.Group.unlock(tbl.Deck[0], bob.unlockKey(1))
+	_, err = tbl.Group.decode(tbl.Group.unlock(m, tbl.Peers[0].unlockKey(1)))
+	a.Error(err)
+	// the keys for a card unlock it in either order
+	m = tbl.Group.unlock(tbl.Deck[0], tbl.Peers[0].unlockKey(0))
+	c, err := tbl.Group.decode(tbl.Group.unlock(m, bob.unlockKey(0)))
+	a.NoError(err)
+	a.Equal(tbl.Peers[0].Hole()[0], c)
+
+	// a tampered card does not decode
+	tbl.Deck[1] = new(big.Int).Add(tbl.Deck[1], big.NewInt(1))
+	a.Error(tbl.Deal(1))
+}
+
+func TestShuffleOrder(t *testing.T) {
+	a := assert.New(t)
+	// no single peer's shuffle decides the order
+	tbl := newTable(a, "ann", "bob")
+	var firsts []*card.Card
+	for i := 0; i < 5; i++ {
+		tbl.Peers[0].RNG = rand.New(rand.NewSource(1))
+		a.NoError(tbl.Shuffle())
+		c, err := tbl.Reveal()
+		a.NoError(err)
+		firsts = append(firsts, c)
+	}
+	differ := false
+	for _, c := range firsts[1:] {
+		differ = differ || c.String() != firsts[0].String()
+	}
+	a.True(differ)
+
+	// with both peers seeded the deal repeats
+	var order []string
+	for i := 0; i < 2; i++ {
+		tbl.Peers[0].RNG = rand.New(rand.NewSource(1))
+		tbl.Peers[1].RNG = rand.New(rand.NewSource(2))
+		a.NoError(tbl.Shuffle())
+		c, err := tbl.Reveal()
+		a.NoError(err)
+		order = append(order, c.String())
+	}
+	a.Equal(order[0], order[1])
+}
+
+func TestGroup(t *testing.T) {
+	a := assert.New(t)
+	_, err := NewGroupPrime(big.NewInt(101))
+	a.Error(err)
+	// 2^127 - 1 is prime but not safe, the powers of two
+	// would lock to one another
+	mersenne, _ := new(big.Int).SetString("170141183460469231731687303715884105727", 10)
+	_, err = NewGroupPrime(mersenne)
+	a.Error(err)
+	_, err = NewGroup(32)
+	a.Error(err)
+	g, err := NewGroup(128)
+	a.NoError(err)
+	a.Equal(128, g.P.BitLen())
+	_, err = NewTable(g, &Peer{Name: "ann"})
+	a.Error(err)
+}
@@ -0,0 +1,255 @@
+package pineapple
+
+import (
+	"fmt"
+
+	"github.com/aultimus/gosouth/deck"
+	"github.com/aultimus/gosouth/hand"
+	"github.com/aultimus/gosouth/headsup"
+)
+
+const (
+	numHoleCards = 3
+	numKept      = 2
+	numFlopCards = 3
+	numCommCards = 5
+)
+
+// VARIANT represents when in the hand hole cards are discarded
+type VARIANT int
+
+const (
+	// Pineapple players discard before the flop
+	Pineapple = VARIANT(iota)
+	// CrazyPineapple players discard after the flop
+	CrazyPineapple = VARIANT(iota)
+)
+
+func (v VARIANT) String() string {
+	if v == CrazyPineapple {
+		return "Crazy Pineapple"
+	}
+	return "Pineapple"
+}
+
+// discardBoardSize is the number of community cards out
+// when players must discard
+func (v VARIANT) discardBoardSize() int {
+	if v == CrazyPineapple {
+		return numFlopCards
+	}
+	return 0
+}
+
+// Table represents a single deal of a Pineapple game.
+// Hands are indexed by seat, seat 0 being first to act.
+type Table struct {
+	Variant  VARIANT
+	Hands    []hand.Hand
+	Board    hand.Hand
+	Discards hand.Hand
+	dealer   *deck.Dealer
+}
+
+// Deal deals three hole cards to each of numPlayers players from deck d,
+// one card at a time in rotation starting with seat 0
+func Deal(v VARIANT, d deck.Deck, numPlayers int) (*Table, error) {
+	if numPlayers < 2 {
+		return nil, fmt.Errorf("%s needs at least 2 players, not %d", v, numPlayers)
+	}
+	if numHoleCards*numPlayers+numCommCards > len(d) {
+		return nil, fmt.Errorf("cannot deal %s to %d players from a deck of %d cards",
+			v, numPlayers, len(d))
+	}
+	t := &Table{
+		Variant: v,
+		Hands:   make([]hand.Hand, numPlayers),
+		dealer:  deck.NewDealer(d),
+	}
+	dealt, err := t.dealer.DealRound(numPlayers, numHoleCards)
+	if err != nil {
+		return nil, err
+	}
+	for p, h := range dealt {
+		t.Hands[p] = hand.Hand(h)
+	}
+	return t, nil
+}
+
+// Discarded returns true once the discard step has been played
+func (t *Table) Discarded() bool {
+	return t.Discards != nil
+}
+
+// Discard plays the discard step, discards holds for each seat the index
+// of the hole card that seat throws away. Pineapple discards must be made
+// before the flop and Crazy Pineapple discards on the flop.
+func (t *Table) Discard(discards []int) error {
+	if t.Discarded() {
+		return fmt.Errorf("discards have already been made")
+	}
+	if len(t.Board) != t.Variant.discardBoardSize() {
+		return fmt.Errorf("%s discards are made with %d board cards, not %d",
+			t.Variant, t.Variant.discardBoardSize(), len(t.Board))
+	}
+	if len(discards) != len(t.Hands) {
+		return fmt.Errorf("expected discards for %d seats, got %d",
+			len(t.Hands), len(discards))
+	}
+	for p, i := range discards {
+		if i < 0 || i >= numHoleCards {
+			return fmt.Errorf("seat %d: discard index %d out of range", p, i)
+		}
+	}
+	for p, i := range discards {
+		t.Discards = append(t.Discards, t.Hands[p][i])
+		t.Hands[p] = Keep(t.Hands[p], i)
+	}
+	return nil
+}
+
+// DealFlop deals the three flop cards
+func (t *Table) DealFlop() error {
+	return t.dealStreet(0, numFlopCards)
+}
+
+// DealTurn deals the turn card
+func (t *Table) DealTurn() error {
+	return t.dealStreet(numFlopCards, 1)
+}
+
+// DealRiver deals the river card
+func (t *Table) DealRiver() error {
+	return t.dealStreet(numFlopCards+1, 1)
+}
+
+func (t *Table) dealStreet(boardSize, n int) error {
+	if len(t.Board) != boardSize {
+		return fmt.Errorf("expected %d board cards to deal the next street, board has %d",
+			boardSize, len(t.Board))
+	}
+	if boardSize >= t.Variant.discardBoardSize() && !t.Discarded() {
+		return fmt.Errorf("%s discards must be made before dealing further cards", t.Variant)
+	}
+	cards, err := t.dealer.Deal(n)
+	if err != nil {
+		return err
+	}
+	t.Board = append(t.Board, cards...)
+	return nil
+}
+
+// Showdown determines the winning index/ drawing indexes
+// once the board is complete
+func (t *Table) Showdown() ([]int, error) {
+	if len(t.Board) != numCommCards {
+		return nil, fmt.Errorf("showdown requires %d board cards, board has %d",
+			numCommCards, len(t.Board))
+	}
+	var hands []hand.Hand
+	for _, h := range t.Hands {
+		hands = append(hands, append(append(hand.Hand(nil), h...), t.Board...))
+	}
+	return hand.Showdown(hands), nil
+}
+
+// Keep returns the two cards kept from hole cards h
+// when the card at index i is discarded
+func Keep(h hand.Hand, i int) hand.Hand {
+	var kept hand.Hand
+	for j, c := range h {
+		if j != i {
+			kept = append(kept, c)
+		}
+	}
+	return kept
+}
+
+// Equity calculates the probability of each hand winning given the board
+// so far. Hands of two cards have already discarded. Hands of three cards
+// are still to discard, which card they will throw away is unknown so each
+// of the three possible discards is weighted equally, with the discarded
+// card out of play.
+func Equity(hands []hand.Hand, board hand.Hand) (*headsup.Result, error) {
+	for i, h := range hands {
+		if len(h) != numKept && len(h) != numHoleCards {
+			return nil, fmt.Errorf("hand %d should have %d or %d cards, not %d",
+				i, numKept, numHoleCards, len(h))
+		}
+	}
+	return equity(hands, board, nil)
+}
+
+// discardProfiles calls f with every combination of discards
+// available to the hands from index i onwards
+func discardProfiles(hands []hand.Hand, i int, kept []hand.Hand, dead hand.Hand,
+	f func([]hand.Hand, hand.Hand) error) error {
+	if i == len(hands) {
+		return f(kept, dead)
+	}
+	h := hands[i]
+	if len(h) == numKept {
+		return discardProfiles(hands, i+1, append(kept, h), dead, f)
+	}
+	for d := range h {
+		k := append(append([]hand.Hand(nil), kept...), Keep(h, d))
+		err := discardProfiles(hands, i+1, k, append(append(hand.Hand(nil), dead...), h[d]), f)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// BestDiscard recommends which of the three hole cards h to discard by
+// calculating the equity of each option against the opponents' hands
+// with headsup.ProbBoard. Opponent hands may still hold three cards, see
+// Equity. With no opponents the options are played against a random hand.
+// It returns the index of the card to discard and the equity of every option.
+func BestDiscard(h hand.Hand, opponents []hand.Hand, board hand.Hand) (int, []float64, error) {
+	if len(h) != numHoleCards {
+		return 0, nil, fmt.Errorf("hand should have %d cards, not %d", numHoleCards, len(h))
+	}
+	equities := make([]float64, numHoleCards)
+	best := 0
+	for i := range h {
+		hands := append([]hand.Hand{Keep(h, i)}, opponents...)
+		// the discarded card is known to us and out of play
+		r, err := equity(hands, board, hand.Hand{h[i]})
+		if err != nil {
+			return 0, nil, err
+		}
+		equities[i] = r.Win[0]
+		if equities[i] > equities[best] {
+			best = i
+		}
+	}
+	return best, equities, nil
+}
+
+// equity averages the equity of every discard profile of hands
+func equity(hands []hand.Hand, board, dead hand.Hand) (*headsup.Result, error) {
+	r := headsup.NewResult(len(hands))
+	if len(hands) == 1 {
+		r = headsup.NewResult(2)
+	}
+	numProfiles := 0
+	err := discardProfiles(hands, 0, nil, dead, func(kept []hand.Hand, d hand.Hand) error {
+		p, err := headsup.ProbBoard(board, d, kept...)
+		if err != nil {
+			return err
+		}
+		for i, w := range p.Win {
+			r.Win[i] += w
+		}
+		numProfiles++
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	for i := range r.Win {
+		r.Win[i] /= float64(numProfiles)
+	}
+	return r, nil
+}
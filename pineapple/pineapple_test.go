@@ -0,0 +1,114 @@
+package pineapple
+
+import (
+	"testing"
+
+	"github.com/aultimus/gosouth/card"
+	"github.com/aultimus/gosouth/deck"
+	"github.com/aultimus/gosouth/hand"
+	"github.com/aultimus/gosouth/headsup"
+	"github.com/stretchr/testify/assert"
+)
+
+func mkHand(cards ...string) hand.Hand {
+	var h hand.Hand
+	for _, s := range cards {
+		h = append(h, card.New(card.RANK(s[:1]), card.SUIT(s[1:])))
+	}
+	return h
+}
+
+func TestPineappleDeal(t *testing.T) {
+	a := assert.New(t)
+	d := deck.New()
+	tbl, err := Deal(Pineapple, d, 3)
+	a.NoError(err)
+	for _, h := range tbl.Hands {
+		a.Equal(3, len(h))
+	}
+	// must discard before the flop
+	a.Error(tbl.DealFlop())
+	a.Error(tbl.Discard([]int{0, 1}))
+	a.Error(tbl.Discard([]int{0, 1, 3}))
+	a.NoError(tbl.Discard([]int{0, 1, 2}))
+	a.Error(tbl.Discard([]int{0, 1, 2}))
+	a.Equal(deck.Deck{d[0], d[4], d[8]}, deck.Deck(tbl.Discards))
+	a.Equal(hand.Hand{d[3], d[6]}, tbl.Hands[0])
+
+	_, err = tbl.Showdown()
+	a.Error(err)
+	a.Error(tbl.DealTurn())
+	a.NoError(tbl.DealFlop())
+	a.NoError(tbl.DealTurn())
+	a.NoError(tbl.DealRiver())
+	a.Error(tbl.DealRiver())
+	a.Equal(hand.Hand(d[9:14]), tbl.Board)
+	w, err := tbl.Showdown()
+	a.NoError(err)
+	a.NotEmpty(w)
+}
+
+func TestCrazyPineappleDeal(t *testing.T) {
+	a := assert.New(t)
+	tbl, err := Deal(CrazyPineapple, deck.New(), 2)
+	a.NoError(err)
+	a.Error(tbl.Discard([]int{0, 0}))
+	a.NoError(tbl.DealFlop())
+	// must discard on the flop
+	a.Error(tbl.DealTurn())
+	a.NoError(tbl.Discard([]int{0, 0}))
+	a.NoError(tbl.DealTurn())
+	a.NoError(tbl.DealRiver())
+
+	_, err = Deal(CrazyPineapple, deck.New(), 16)
+	a.Error(err)
+}
+
+func TestEquity(t *testing.T) {
+	a := assert.New(t)
+	board := mkHand("KS", "KD", "7H", "3C")
+	h1 := mkHand("AS", "AH")
+	h2 := mkHand("QC", "QD")
+
+	// with every discard made equity is plain headsup equity
+	r, err := Equity([]hand.Hand{h1, h2}, board)
+	a.NoError(err)
+	e, err := headsup.ProbBoard(board, nil, h1, h2)
+	a.NoError(err)
+	a.Equal(e.Win, r.Win)
+
+	// an unknown discard from three cards averages the three options
+	h3 := mkHand("QC", "QD", "2S")
+	r, err = Equity([]hand.Hand{h1, h3}, board)
+	a.NoError(err)
+	var expected float64
+	for i := range h3 {
+		e, err := headsup.ProbBoard(board, hand.Hand{h3[i]}, h1, Keep(h3, i))
+		a.NoError(err)
+		expected += e.Win[0] / 3
+	}
+	a.InDelta(expected, r.Win[0], 1e-9)
+	a.InDelta(100, r.Win[0]+r.Win[1], 1e-9)
+
+	_, err = Equity([]hand.Hand{h1, mkHand("QC")}, board)
+	a.Error(err)
+}
+
+func TestBestDiscard(t *testing.T) {
+	a := assert.New(t)
+	board := mkHand("KS", "KD", "7H", "3C")
+	i, equities, err := BestDiscard(mkHand("2C", "AS", "AH"), []hand.Hand{mkHand("QC", "QD", "JS")}, board)
+	a.NoError(err)
+	a.Equal(0, i)
+	a.Equal(3, len(equities))
+	a.True(equities[0] > equities[1])
+	a.True(equities[0] > equities[2])
+
+	// against a random hand
+	i, _, err = BestDiscard(mkHand("7C", "7S", "2H"), nil, board)
+	a.NoError(err)
+	a.Equal(2, i)
+
+	_, _, err = BestDiscard(mkHand("7C", "7S"), nil, board)
+	a.Error(err)
+}
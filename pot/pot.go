@@ -0,0 +1,197 @@
+package pot
+
+import (
+	"fmt"
+	"sort"
+)
+
+// Pot is an amount of chips and the players eligible to win it,
+// once awarded Shares holds the chips won by each of the Winners
+type Pot struct {
+	Amount   int
+	Eligible []int
+	Winners  []int
+	Shares   []int
+}
+
+// OddChipRule orders the winners of a pot, when a pot cannot be split
+// evenly the leftover chips are given out one unit at a time in this order
+type OddChipRule func(winners []int) []int
+
+// LowestIndex gives odd chips to the winners in player index order
+func LowestIndex(winners []int) []int {
+	w := append([]int(nil), winners...)
+	sort.Ints(w)
+	return w
+}
+
+// LeftOfButton gives odd chips to the winners in clockwise order starting
+// with the first player after the button, the usual rule for flop games
+func LeftOfButton(button, numPlayers int) OddChipRule {
+	return func(winners []int) []int {
+		w := append([]int(nil), winners...)
+		sort.Slice(w, func(i, j int) bool {
+			return (w[i]-button-1+numPlayers)%numPlayers < (w[j]-button-1+numPlayers)%numPlayers
+		})
+		return w
+	}
+}
+
+// Manager tracks the chips put in by each player and
+// builds and awards the main pot and side pots
+type Manager struct {
+	// OddChip orders winners for odd chips, nil is LowestIndex
+	OddChip OddChipRule
+	// Unit is the smallest chip in play that a pot can be split into,
+	// zero is a unit of one
+	Unit int
+
+	contributions []int
+	folded        []bool
+}
+
+// New creates a Manager for numPlayers players
+func New(numPlayers int) *Manager {
+	return &Manager{
+		contributions: make([]int, numPlayers),
+		folded:        make([]bool, numPlayers),
+	}
+}
+
+// Add records amount chips put in by a player, negative amounts
+// take back chips, such as an uncalled bet
+func (m *Manager) Add(player, amount int) {
+	m.contributions[player] += amount
+}
+
+// Fold marks a player as no longer eligible to win any pot,
+// chips they have put in stay in the pots
+func (m *Manager) Fold(player int) {
+	m.folded[player] = true
+}
+
+// Contribution returns the chips put in by a player
+func (m *Manager) Contribution(player int) int {
+	return m.contributions[player]
+}
+
+// Total returns all chips put in
+func (m *Manager) Total() int {
+	total := 0
+	for _, c := range m.contributions {
+		total += c
+	}
+	return total
+}
+
+// Pots layers the chips put in into a main pot and side pots, one for
+// each distinct amount put in by players who have not folded. Each pot is
+// contested by the players who put in at least that amount. Chips put in
+// by folded players beyond the largest live amount go to the last pot.
+func (m *Manager) Pots() []*Pot {
+	var levels []int
+	seen := make(map[int]bool)
+	for i, c := range m.contributions {
+		if !m.folded[i] && c > 0 && !seen[c] {
+			levels = append(levels, c)
+			seen[c] = true
+		}
+	}
+	sort.Ints(levels)
+
+	var pots []*Pot
+	prev := 0
+	for _, level := range levels {
+		p := &Pot{}
+		for i, c := range m.contributions {
+			p.Amount += min(c, level) - min(c, prev)
+			if !m.folded[i] && c >= level {
+				p.Eligible = append(p.Eligible, i)
+			}
+		}
+		pots = append(pots, p)
+		prev = level
+	}
+	for _, c := range m.contributions {
+		if c > prev && len(pots) > 0 {
+			pots[len(pots)-1].Amount += c - prev
+		}
+	}
+	return pots
+}
+
+// Award builds the pots and splits each between its winners. showdown is
+// given the eligible players of a contested pot and returns those with
+// the best hand. It returns the pots, with winners set, and the chips
+// won by each player.
+func (m *Manager) Award(showdown func(eligible []int) []int) ([]*Pot, []int, error) {
+	pots := m.Pots()
+	if len(pots) == 0 && m.Total() > 0 {
+		return nil, nil, fmt.Errorf("every player has folded, %d chips cannot be awarded", m.Total())
+	}
+	won := make([]int, len(m.contributions))
+	for _, p := range pots {
+		p.Winners = p.Eligible
+		if len(p.Eligible) > 1 {
+			p.Winners = showdown(p.Eligible)
+		}
+		if err := m.checkWinners(p); err != nil {
+			return nil, nil, err
+		}
+		p.Shares = m.Split(p.Amount, p.Winners)
+		for i, w := range p.Shares {
+			won[p.Winners[i]] += w
+		}
+	}
+	return pots, won, nil
+}
+
+func (m *Manager) checkWinners(p *Pot) error {
+	if len(p.Winners) == 0 {
+		return fmt.Errorf("pot of %d has no winner", p.Amount)
+	}
+	for _, w := range p.Winners {
+		eligible := false
+		for _, e := range p.Eligible {
+			eligible = eligible || e == w
+		}
+		if !eligible {
+			return fmt.Errorf("player %d is not eligible to win pot of %d", w, p.Amount)
+		}
+	}
+	return nil
+}
+
+// Split divides amount between winners, returning each winner's share in
+// the order given. Shares are whole units, leftover units are handed out
+// by the OddChip rule and any remainder smaller than a unit goes to the
+// first winner by that rule.
+func (m *Manager) Split(amount int, winners []int) []int {
+	unit := m.Unit
+	if unit <= 0 {
+		unit = 1
+	}
+	rule := m.OddChip
+	if rule == nil {
+		rule = LowestIndex
+	}
+	units := amount / unit
+	share := units / len(winners) * unit
+	odd := units % len(winners)
+
+	byPlayer := make(map[int]int)
+	for i, w := range rule(winners) {
+		byPlayer[w] = share
+		if i < odd {
+			byPlayer[w] += unit
+		}
+		if i == 0 {
+			byPlayer[w] += amount % unit
+		}
+	}
+	shares := make([]int, len(winners))
+	for i, w := range winners {
+		shares[i] = byPlayer[w]
+	}
+	return shares
+}
@@ -0,0 +1,180 @@
+package pot
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// manager puts in the given contributions, folding the listed players
+func manager(contributions []int, folded ...int) *Manager {
+	m := New(len(contributions))
+	for i, c := range contributions {
+		m.Add(i, c)
+	}
+	for _, f := range folded {
+		m.Fold(f)
+	}
+	return m
+}
+
+// ranked returns a showdown where players with a lower
+// strength win, equal strengths split
+func ranked(strength ...int) func([]int) []int {
+	return func(eligible []int) []int {
+		var winners []int
+		best := -1
+		for _, e := range eligible {
+			switch {
+			case best == -1 || strength[e] < best:
+				winners = []int{e}
+				best = strength[e]
+			case strength[e] == best:
+				winners = append(winners, e)
+			}
+		}
+		return winners
+	}
+}
+
+func TestThreeWayAllIn(t *testing.T) {
+	a := assert.New(t)
+	m := manager([]int{50, 100, 200})
+	a.Equal(350, m.Total())
+	pots, won, err := m.Award(ranked(2, 1, 0))
+	a.NoError(err)
+	a.Equal([]*Pot{
+		{Amount: 150, Eligible: []int{0, 1, 2}, Winners: []int{2}, Shares: []int{150}},
+		{Amount: 100, Eligible: []int{1, 2}, Winners: []int{2}, Shares: []int{100}},
+		// uncontested, only player 2 put in this much
+		{Amount: 100, Eligible: []int{2}, Winners: []int{2}, Shares: []int{100}},
+	}, pots)
+	a.Equal([]int{0, 0, 350}, won)
+
+	// the shortest stack wins only the main pot
+	_, won, err = m.Award(ranked(0, 1, 2))
+	a.NoError(err)
+	a.Equal([]int{150, 100, 100}, won)
+}
+
+func TestEqualAllIns(t *testing.T) {
+	a := assert.New(t)
+	// two players all in for the same amount share a level
+	m := manager([]int{40, 40, 100, 100})
+	pots := m.Pots()
+	a.Equal([]*Pot{
+		{Amount: 160, Eligible: []int{0, 1, 2, 3}},
+		{Amount: 120, Eligible: []int{2, 3}},
+	}, pots)
+
+	_, won, err := m.Award(ranked(0, 0, 1, 1))
+	a.NoError(err)
+	a.Equal([]int{80, 80, 60, 60}, won)
+}
+
+func TestFoldedDeadMoney(t *testing.T) {
+	a := assert.New(t)
+	// player 1 folds after putting in more than the all in player 0,
+	// its chips are split between the levels it reached
+	m := manager([]int{30, 60, 100, 100}, 1)
+	a.Equal([]*Pot{
+		{Amount: 120, Eligible: []int{0, 2, 3}},
+		{Amount: 170, Eligible: []int{2, 3}},
+	}, m.Pots())
+
+	// a folded player put in more than anyone still in
+	m = manager([]int{30, 80, 50}, 1)
+	a.Equal([]*Pot{
+		{Amount: 90, Eligible: []int{0, 2}},
+		{Amount: 70, Eligible: []int{2}},
+	}, m.Pots())
+	_, won, err := m.Award(ranked(0, 0, 1))
+	a.NoError(err)
+	a.Equal([]int{90, 0, 70}, won)
+}
+
+func TestFiveWayAllIn(t *testing.T) {
+	a := assert.New(t)
+	m := manager([]int{10, 25, 25, 60, 100, 5}, 5)
+	pots := m.Pots()
+	a.Equal([]*Pot{
+		{Amount: 55, Eligible: []int{0, 1, 2, 3, 4}},
+		{Amount: 60, Eligible: []int{1, 2, 3, 4}},
+		{Amount: 70, Eligible: []int{3, 4}},
+		{Amount: 40, Eligible: []int{4}},
+	}, pots)
+
+	// 1 and 2 split the two pots they are in, 3 beats 4 for the next
+	pots, won, err := m.Award(ranked(3, 0, 0, 1, 2, 0))
+	a.NoError(err)
+	a.Equal([]int{1, 2}, pots[0].Winners)
+	a.Equal([]int{28, 27}, pots[0].Shares)
+	a.Equal([]int{0, 58, 57, 70, 40, 0}, won)
+	total := 0
+	for _, w := range won {
+		total += w
+	}
+	a.Equal(m.Total(), total)
+}
+
+func TestOddChips(t *testing.T) {
+	a := assert.New(t)
+	m := manager([]int{11, 11, 11, 0, 0})
+	_, won, err := m.Award(ranked(0, 0, 0))
+	a.NoError(err)
+	a.Equal([]int{11, 11, 11, 0, 0}, won)
+
+	m = manager([]int{10, 10, 10, 1, 0}, 3)
+	// 31 between three, the odd chip to the lowest index by default
+	_, won, err = m.Award(ranked(0, 0, 0))
+	a.NoError(err)
+	a.Equal([]int{11, 10, 10, 0, 0}, won)
+
+	// button on 1, so 2 is first to the left
+	m.OddChip = LeftOfButton(1, 5)
+	_, won, err = m.Award(ranked(0, 0, 0))
+	a.NoError(err)
+	a.Equal([]int{10, 10, 11, 0, 0}, won)
+
+	// two odd chips go clockwise from the button, wrapping around
+	m = manager([]int{10, 10, 10, 2, 0}, 3)
+	m.OddChip = LeftOfButton(1, 5)
+	_, won, err = m.Award(ranked(0, 0, 0))
+	a.NoError(err)
+	a.Equal([]int{11, 10, 11, 0, 0}, won)
+}
+
+func TestSplitUnit(t *testing.T) {
+	a := assert.New(t)
+	m := New(3)
+	m.Unit = 5
+	m.OddChip = LeftOfButton(0, 3)
+	// 35 in units of 5 is 20 and 15 or 15, 10 and 10
+	a.Equal([]int{15, 20}, m.Split(35, []int{0, 1}))
+	a.Equal([]int{15, 10, 10}, m.Split(35, []int{1, 2, 0}))
+	// a remainder smaller than a unit goes with the first odd chip
+	a.Equal([]int{10, 17}, m.Split(27, []int{0, 1}))
+}
+
+func TestAwardErrors(t *testing.T) {
+	a := assert.New(t)
+	m := manager([]int{10, 10}, 0, 1)
+	a.Empty(m.Pots())
+	_, _, err := m.Award(ranked(0, 0))
+	a.Error(err)
+
+	m = manager([]int{10, 10, 10})
+	_, _, err = m.Award(func([]int) []int { return nil })
+	a.Error(err)
+	m.Fold(2)
+	_, _, err = m.Award(func([]int) []int { return []int{2} })
+	a.Error(err)
+}
+
+func TestUncalledReturned(t *testing.T) {
+	a := assert.New(t)
+	m := manager([]int{100, 40})
+	m.Add(0, -60)
+	a.Equal(40, m.Contribution(0))
+	a.Equal([]*Pot{{Amount: 80, Eligible: []int{0, 1}}}, m.Pots())
+}
@@ -0,0 +1,55 @@
+package replay
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/aultimus/gosouth/card"
+	"github.com/aultimus/gosouth/hand"
+)
+
+// Range is the hole cards a player may hold, each equally likely
+type Range []hand.Hand
+
+// ParseRange reads a comma separated range of hand types and hands, such
+// as "QQ, AKs, AQo, KJ, 7h6h". A pair is its 6 combinations, a suited hand
+// 4, an offsuit hand 12 and a hand without s or o all 16.
+func ParseRange(s string) (Range, error) {
+	var r Range
+	for _, part := range strings.Split(s, ",") {
+		part = strings.TrimSpace(part)
+		if len(part) == 4 {
+			h, err := hand.Parse(part)
+			if err != nil {
+				return nil, err
+			}
+			r = append(r, h)
+			continue
+		}
+		if len(part) != 2 && len(part) != 3 {
+			return nil, fmt.Errorf("%q is not a hand type", part)
+		}
+		u := strings.ToUpper(part)
+		r1, r2 := card.RANK(u[:1]), card.RANK(u[1:2])
+		_, ok1 := card.RankIndexes[r1]
+		_, ok2 := card.RankIndexes[r2]
+		kind := u[2:]
+		if !ok1 || !ok2 || (kind != "" && kind != "S" && kind != "O") || (r1 == r2 && kind != "") {
+			return nil, fmt.Errorf("%q is not a hand type", part)
+		}
+		for i, s1 := range card.Suits {
+			for j, s2 := range card.Suits {
+				switch {
+				case r1 == r2 && j <= i:
+					continue
+				case kind == "S" && s1 != s2:
+					continue
+				case kind == "O" && s1 == s2:
+					continue
+				}
+				r = append(r, hand.Hand{card.New(r1, s1), card.New(r2, s2)})
+			}
+		}
+	}
+	return r, nil
+}
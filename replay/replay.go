@@ -0,0 +1,289 @@
+package replay
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/aultimus/gosouth/game"
+	"github.com/aultimus/gosouth/hand"
+	"github.com/aultimus/gosouth/headsup"
+	"github.com/aultimus/gosouth/history"
+)
+
+// boardSize is the number of community cards out on each street
+var boardSize = []int{0, 3, 4, 5}
+
+// Options configure a replay
+type Options struct {
+	// Ranges holds the hands a player, by name, is assumed to hold
+	// when their cards are not in the record
+	Ranges map[string]Range
+	// Preflop enables equity before the flop, which enumerates every
+	// board and takes seconds for each matchup
+	Preflop bool
+}
+
+// Decision is an action annotated with the state of the hand
+// when it was made. Equity is nil where it was not computed.
+type Decision struct {
+	Action game.Action
+	// Pot is all chips put in before the action
+	Pot    int
+	ToCall int
+	// PotOdds is the percentage equity needed to call
+	PotOdds float64
+	// Equity holds each player's percentage equity, by player index
+	Equity []float64
+	// EV is the chips the action is expected to win compared to folding,
+	// assuming every bet is called and the hand is then checked down
+	EV float64
+}
+
+// Street is a betting round of a replayed hand
+type Street struct {
+	Street    game.STREET
+	Board     hand.Hand
+	Equity    []float64
+	Decisions []*Decision
+}
+
+// Report is a hand replayed street by street
+type Report struct {
+	Record  *history.Record
+	Streets []*Street
+}
+
+// replayer holds the state of the hand as it is replayed
+type replayer struct {
+	rec    *history.Record
+	opts   Options
+	pot    int
+	bets   []int
+	left   []int
+	folded []bool
+	cache  map[string][]float64
+}
+
+// Replay steps through a recorded hand working out each player's equity,
+// the pot odds offered and the EV of every action. A player in the hand
+// whose cards are unknown must have a range in opts.
+func Replay(r *history.Record, opts Options) (*Report, error) {
+	n := len(r.Players)
+	rp := &replayer{
+		rec:    r,
+		opts:   opts,
+		bets:   make([]int, n),
+		left:   make([]int, n),
+		folded: make([]bool, n),
+		cache:  make(map[string][]float64),
+	}
+	for i, p := range r.Players {
+		rp.pot += p.Ante + p.Blind
+		rp.bets[i] = p.Blind
+		rp.left[i] = p.Stack - p.Ante - p.Blind
+	}
+
+	rep := &Report{Record: r}
+	next := 0
+	for street := game.Preflop; street <= game.River && boardSize[street] <= len(r.Board); street++ {
+		if street > game.Preflop {
+			rp.bets = make([]int, n)
+		}
+		s := &Street{Street: street, Board: r.Board[:boardSize[street]]}
+		var err error
+		if s.Equity, err = rp.equity(s); err != nil {
+			return nil, err
+		}
+		for ; next < len(r.Actions) && r.Actions[next].Street == street; next++ {
+			d, err := rp.decide(s, r.Actions[next])
+			if err != nil {
+				return nil, err
+			}
+			s.Decisions = append(s.Decisions, d)
+		}
+		rep.Streets = append(rep.Streets, s)
+	}
+	if next < len(r.Actions) {
+		return nil, fmt.Errorf("hand %d has %s actions without the board for it", r.ID, r.Actions[next].Street)
+	}
+	return rep, nil
+}
+
+// decide annotates an action then applies it
+func (rp *replayer) decide(s *Street, a game.Action) (*Decision, error) {
+	i := a.Seat
+	current := 0
+	for _, b := range rp.bets {
+		current = max(current, b)
+	}
+	d := &Decision{Action: a, Pot: rp.pot, ToCall: max(0, min(current-rp.bets[i], rp.left[i]))}
+	if d.ToCall > 0 {
+		d.PotOdds = 100 * float64(d.ToCall) / float64(rp.pot+d.ToCall)
+	}
+	var err error
+	if d.Equity, err = rp.equity(s); err != nil {
+		return nil, err
+	}
+
+	added := 0
+	switch a.Type {
+	case game.Fold:
+		rp.folded[i] = true
+	case game.Call:
+		added = a.Amount
+	case game.Bet, game.Raise:
+		added = a.Amount - rp.bets[i]
+	}
+	if d.Equity != nil && a.Type != game.Fold {
+		// everyone still in with chips calls a bet
+		called := 0
+		if a.Type == game.Bet || a.Type == game.Raise {
+			for j := range rp.bets {
+				if j != i && !rp.folded[j] {
+					called += min(a.Amount-rp.bets[j], rp.left[j])
+				}
+			}
+		}
+		d.EV = d.Equity[i]/100*float64(rp.pot+added+called) - float64(added)
+	}
+	rp.pot += added
+	rp.bets[i] += added
+	rp.left[i] -= added
+	return d, nil
+}
+
+// equity returns each player's equity with the cards on the street,
+// results are cached as they only change when a player folds
+func (rp *replayer) equity(s *Street) ([]float64, error) {
+	if s.Street == game.Preflop && !rp.opts.Preflop {
+		return nil, nil
+	}
+	var live []int
+	var dead hand.Hand
+	for i, f := range rp.folded {
+		if !f {
+			live = append(live, i)
+		} else {
+			dead = append(dead, rp.rec.Players[i].Hole...)
+		}
+	}
+	key := fmt.Sprint(s.Street, live)
+	if eq, ok := rp.cache[key]; ok {
+		return eq, nil
+	}
+
+	eq := make([]float64, len(rp.folded))
+	if len(live) == 1 {
+		eq[live[0]] = 100
+		rp.cache[key] = eq
+		return eq, nil
+	}
+	choices := make([]Range, len(live))
+	for k, i := range live {
+		p := rp.rec.Players[i]
+		switch {
+		case p.Hole != nil:
+			choices[k] = Range{p.Hole}
+		case rp.opts.Ranges[p.Name] != nil:
+			choices[k] = rp.opts.Ranges[p.Name]
+		default:
+			return nil, fmt.Errorf("%s's cards are not known and they have no range", p.Name)
+		}
+	}
+
+	used := make(map[string]bool)
+	for _, c := range append(append(hand.Hand(nil), s.Board...), dead...) {
+		used[c.String()] = true
+	}
+	hands := make([]hand.Hand, len(live))
+	count := 0
+	// try every combination of hands that do not share a card
+	var walk func(k int) error
+	walk = func(k int) error {
+		if k == len(live) {
+			res, err := headsup.ProbBoard(s.Board, dead, hands...)
+			if err != nil {
+				return err
+			}
+			for j, i := range live {
+				eq[i] += res.Win[j]
+			}
+			count++
+			return nil
+		}
+		for _, h := range choices[k] {
+			if used[h[0].String()] || used[h[1].String()] || h[0].String() == h[1].String() {
+				continue
+			}
+			used[h[0].String()], used[h[1].String()] = true, true
+			hands[k] = h
+			err := walk(k + 1)
+			used[h[0].String()], used[h[1].String()] = false, false
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	if err := walk(0); err != nil {
+		return nil, err
+	}
+	if count == 0 {
+		return nil, fmt.Errorf("no hands in the ranges are possible on the %s", s.Street)
+	}
+	for i := range eq {
+		eq[i] /= float64(count)
+	}
+	rp.cache[key] = eq
+	return eq, nil
+}
+
+// equityString lists the equity of the players in the hand
+func (r *Report) equityString(eq []float64, folded map[int]bool) string {
+	if eq == nil {
+		return "equity not computed"
+	}
+	var s []string
+	for i, e := range eq {
+		if !folded[i] {
+			s = append(s, fmt.Sprintf("%s %.1f%%", r.Record.Players[i].Name, e))
+		}
+	}
+	return "equity " + strings.Join(s, ", ")
+}
+
+// String writes the report street by street,
+// a line for each action made
+func (r *Report) String() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Hand #%d\n", r.Record.ID)
+	folded := make(map[int]bool)
+	for _, s := range r.Streets {
+		fmt.Fprintf(&b, "%s", s.Street)
+		if len(s.Board) > 0 {
+			fmt.Fprintf(&b, " %s", s.Board)
+		}
+		fmt.Fprintf(&b, ": %s\n", r.equityString(s.Equity, folded))
+		for _, d := range s.Decisions {
+			a := d.Action
+			fmt.Fprintf(&b, "  %s %s", r.Record.Players[a.Seat].Name, a.Type)
+			if a.Type == game.Bet || a.Type == game.Raise {
+				fmt.Fprintf(&b, " to %d", a.Amount)
+			} else if a.Type == game.Call {
+				fmt.Fprintf(&b, " %d", a.Amount)
+			}
+			fmt.Fprintf(&b, ": pot %d", d.Pot)
+			if d.ToCall > 0 {
+				fmt.Fprintf(&b, ", to call %d (pot odds %.1f%%)", d.ToCall, d.PotOdds)
+			}
+			if d.Equity != nil {
+				fmt.Fprintf(&b, ", equity %.1f%%, EV %+.1f", d.Equity[a.Seat], d.EV)
+			}
+			b.WriteString("\n")
+			if a.Type == game.Fold {
+				folded[a.Seat] = true
+			}
+		}
+	}
+	return b.String()
+}
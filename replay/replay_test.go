@@ -0,0 +1,135 @@
+package replay
+
+import (
+	"testing"
+
+	"github.com/aultimus/gosouth/game"
+	"github.com/aultimus/gosouth/hand"
+	"github.com/aultimus/gosouth/history"
+	"github.com/stretchr/testify/assert"
+)
+
+func mkHand(s string) hand.Hand {
+	h, err := hand.Parse(s)
+	if err != nil {
+		panic(err)
+	}
+	return h
+}
+
+func action(seat int, street game.STREET, t game.ACTION, amount int) game.Action {
+	return game.Action{Seat: seat, Street: street, Type: t, Amount: amount}
+}
+
+// acesVsKings is a heads up hand checked down but for a bet on the flop
+func acesVsKings() *history.Record {
+	return &history.Record{
+		ID: 3, SmallBlind: 1, BigBlind: 2, Button: 0,
+		Players: []*history.Player{
+			{Name: "ann", Stack: 100, Blind: 1, Hole: mkHand("AS AD")},
+			{Name: "bob", Stack: 100, Blind: 2, Hole: mkHand("KS KD")},
+		},
+		Board: mkHand("3C 7D 9H TS 4D"),
+		Actions: []game.Action{
+			action(0, game.Preflop, game.Call, 1),
+			action(1, game.Preflop, game.Check, 0),
+			action(1, game.Flop, game.Check, 0),
+			action(0, game.Flop, game.Bet, 10),
+			action(1, game.Flop, game.Call, 10),
+			action(1, game.Turn, game.Check, 0),
+			action(0, game.Turn, game.Check, 0),
+			action(1, game.River, game.Check, 0),
+			action(0, game.River, game.Check, 0),
+		},
+	}
+}
+
+func TestReplay(t *testing.T) {
+	a := assert.New(t)
+	rep, err := Replay(acesVsKings(), Options{})
+	a.NoError(err)
+	a.Equal(4, len(rep.Streets))
+	a.Nil(rep.Streets[0].Equity)
+
+	// kings win with a king on the turn or river but not a king
+	// and an ace, 83 of the 990 runouts
+	kings := 83.0 / 990
+	flop := rep.Streets[1]
+	a.InDelta(100*kings, flop.Equity[1], 1e-9)
+	a.InDelta(100*(1-kings), flop.Equity[0], 1e-9)
+	check, bet, call := flop.Decisions[0], flop.Decisions[1], flop.Decisions[2]
+	a.Equal(4, check.Pot)
+	a.InDelta(kings*4, check.EV, 1e-9)
+	a.Equal(0, bet.ToCall)
+	a.InDelta((1-kings)*24-10, bet.EV, 1e-9)
+	a.Equal(14, call.Pot)
+	a.Equal(10, call.ToCall)
+	a.InDelta(100.0*10/24, call.PotOdds, 1e-9)
+	a.InDelta(kings*24-10, call.EV, 1e-9)
+
+	a.InDelta(100.0*2/44, rep.Streets[2].Equity[1], 0.01)
+	a.Equal([]float64{100, 0}, rep.Streets[3].Equity)
+
+	a.Equal(`Hand #3
+Preflop: equity not computed
+  ann call 1: pot 3, to call 1 (pot odds 25.0%)
+  bob check: pot 4
+Flop [3C 7D 9H]: equity ann 91.6%, bob 8.4%
+  bob check: pot 4, equity 8.4%, EV +0.3
+  ann bet to 10: pot 4, equity 91.6%, EV +12.0
+  bob call 10: pot 14, to call 10 (pot odds 41.7%), equity 8.4%, EV -8.0
+Turn [3C 7D 9H TS]: equity ann 95.5%, bob 4.5%
+  bob check: pot 24, equity 4.5%, EV +1.1
+  ann check: pot 24, equity 95.5%, EV +22.9
+River [3C 7D 9H TS 4D]: equity ann 100.0%, bob 0.0%
+  bob check: pot 24, equity 0.0%, EV +0.0
+  ann check: pot 24, equity 100.0%, EV +24.0
+`, rep.String())
+}
+
+func TestReplayRanges(t *testing.T) {
+	a := assert.New(t)
+	r := acesVsKings()
+	r.Players[1].Hole = nil
+	_, err := Replay(r, Options{})
+	a.Error(err)
+
+	kings, err := ParseRange("KK")
+	a.NoError(err)
+	rep, err := Replay(r, Options{Ranges: map[string]Range{"bob": kings}})
+	a.NoError(err)
+	a.InDelta(100*83.0/990, rep.Streets[1].Equity[1], 1e-9)
+
+	// a range that is blocked by the board and the known hand
+	blocked, err := ParseRange("AsKs, 3c3d")
+	a.NoError(err)
+	_, err = Replay(r, Options{Ranges: map[string]Range{"bob": blocked}})
+	a.Error(err)
+
+	// a fold leaves the other player with all the equity
+	r = acesVsKings()
+	r.Actions = append(r.Actions[:4], action(1, game.Flop, game.Fold, 0))
+	r.Board = r.Board[:3]
+	rep, err = Replay(r, Options{})
+	a.NoError(err)
+	a.Equal(2, len(rep.Streets))
+	a.Equal(game.Fold, rep.Streets[1].Decisions[2].Action.Type)
+	a.Equal(0.0, rep.Streets[1].Decisions[2].EV)
+	a.Contains(rep.String(), "bob fold: pot 14, to call 10 (pot odds 41.7%), equity 8.4%, EV +0.0")
+}
+
+func TestParseRange(t *testing.T) {
+	a := assert.New(t)
+	for s, n := range map[string]int{"QQ": 6, "AKs": 4, "AKo": 12, "kj": 16, "7h6h": 1, "QQ, AKs, 7h6h": 11} {
+		r, err := ParseRange(s)
+		a.NoError(err, s)
+		a.Equal(n, len(r), s)
+	}
+	r, err := ParseRange("AKs")
+	a.NoError(err)
+	a.Equal(mkHand("AC KC"), r[0])
+	for _, s := range []string{"", "AKx", "QQs", "A", "1K", "AsKz"} {
+		_, err := ParseRange(s)
+		a.Error(err, s)
+	}
+}
@@ -0,0 +1,82 @@
+package stats
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"math"
+	"strconv"
+)
+
+// row is a player's statistics as written to CSV and JSON,
+// percentages and bb/100 are rounded to one decimal place
+type row struct {
+	Player         string  `json:"player"`
+	Position       string  `json:"position,omitempty"`
+	Hands          int     `json:"hands"`
+	VPIP           float64 `json:"vpip"`
+	PFR            float64 `json:"pfr"`
+	ThreeBet       float64 `json:"three_bet"`
+	FoldToThreeBet float64 `json:"fold_to_three_bet"`
+	CBet           float64 `json:"cbet"`
+	AF             float64 `json:"af"`
+	WTSD           float64 `json:"wtsd"`
+	WSD            float64 `json:"wsd"`
+	BBPer100       float64 `json:"bb_per_100"`
+}
+
+var header = []string{"player", "position", "hands", "vpip", "pfr", "three_bet",
+	"fold_to_three_bet", "cbet", "af", "wtsd", "wsd", "bb_per_100"}
+
+func round(f float64) float64 {
+	return math.Round(f*10) / 10
+}
+
+func toRow(s *Stats) row {
+	return row{
+		Player:         s.Player,
+		Position:       s.Position,
+		Hands:          s.Hands,
+		VPIP:           round(s.VPIP()),
+		PFR:            round(s.PFR()),
+		ThreeBet:       round(s.ThreeBet()),
+		FoldToThreeBet: round(s.FoldToThreeBet()),
+		CBet:           round(s.CBet()),
+		AF:             round(s.AF()),
+		WTSD:           round(s.WTSD()),
+		WSD:            round(s.WSD()),
+		BBPer100:       round(s.BBPer100()),
+	}
+}
+
+// WriteCSV writes statistics as CSV with a header row
+func WriteCSV(w io.Writer, stats []*Stats) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write(header); err != nil {
+		return err
+	}
+	for _, s := range stats {
+		r := toRow(s)
+		f := func(v float64) string {
+			return strconv.FormatFloat(v, 'f', 1, 64)
+		}
+		err := cw.Write([]string{r.Player, r.Position, strconv.Itoa(r.Hands), f(r.VPIP), f(r.PFR),
+			f(r.ThreeBet), f(r.FoldToThreeBet), f(r.CBet), f(r.AF), f(r.WTSD), f(r.WSD), f(r.BBPer100)})
+		if err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// WriteJSON writes statistics as a JSON array
+func WriteJSON(w io.Writer, stats []*Stats) error {
+	rows := make([]row, len(stats))
+	for i, s := range stats {
+		rows[i] = toRow(s)
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(rows)
+}
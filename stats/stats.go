@@ -0,0 +1,295 @@
+package stats
+
+import (
+	"sort"
+	"time"
+
+	"github.com/aultimus/gosouth/game"
+	"github.com/aultimus/gosouth/history"
+)
+
+// Filter selects the hands that are counted, zero fields match any hand
+type Filter struct {
+	Currency   string
+	SmallBlind int
+	BigBlind   int
+	// From and To bound the time of a hand, From inclusive, To exclusive
+	From time.Time
+	To   time.Time
+}
+
+// Match returns true if a hand passes the filter
+func (f Filter) Match(r *history.Record) bool {
+	switch {
+	case f.Currency != "" && f.Currency != r.Currency:
+		return false
+	case f.SmallBlind != 0 && f.SmallBlind != r.SmallBlind:
+		return false
+	case f.BigBlind != 0 && f.BigBlind != r.BigBlind:
+		return false
+	case !f.From.IsZero() && r.Time.Before(f.From):
+		return false
+	case !f.To.IsZero() && !r.Time.Before(f.To):
+		return false
+	}
+	return true
+}
+
+// Counts are the totals a player's statistics are worked out from.
+// A chance is a spot where the player could have made the play.
+type Counts struct {
+	Hands           int
+	VPIP            int
+	PFR             int
+	ThreeBetChances int
+	ThreeBets       int
+	// FoldToThreeBetChances counts the opening raises that were re-raised
+	FoldToThreeBetChances int
+	FoldsToThreeBet       int
+	// CBetChances counts the flops where the preflop
+	// aggressor could bet first
+	CBetChances int
+	CBets       int
+	// Aggressive and Calls count bets and raises, and calls, after the flop
+	Aggressive   int
+	Calls        int
+	SawFlop      int
+	Showdowns    int
+	ShowdownsWon int
+	// BigBlinds is the net won in big blinds
+	BigBlinds float64
+}
+
+func (c *Counts) add(o Counts) {
+	c.Hands += o.Hands
+	c.VPIP += o.VPIP
+	c.PFR += o.PFR
+	c.ThreeBetChances += o.ThreeBetChances
+	c.ThreeBets += o.ThreeBets
+	c.FoldToThreeBetChances += o.FoldToThreeBetChances
+	c.FoldsToThreeBet += o.FoldsToThreeBet
+	c.CBetChances += o.CBetChances
+	c.CBets += o.CBets
+	c.Aggressive += o.Aggressive
+	c.Calls += o.Calls
+	c.SawFlop += o.SawFlop
+	c.Showdowns += o.Showdowns
+	c.ShowdownsWon += o.ShowdownsWon
+	c.BigBlinds += o.BigBlinds
+}
+
+func percent(n, d int) float64 {
+	if d == 0 {
+		return 0
+	}
+	return 100 * float64(n) / float64(d)
+}
+
+// Stats are a player's statistics, over every position
+// if Position is empty
+type Stats struct {
+	Player   string
+	Position string
+	Counts
+}
+
+// VPIP returns the percentage of hands where the player
+// voluntarily put chips in preflop
+func (s *Stats) VPIP() float64 {
+	return percent(s.Counts.VPIP, s.Hands)
+}
+
+// PFR returns the percentage of hands where the player raised preflop
+func (s *Stats) PFR() float64 {
+	return percent(s.Counts.PFR, s.Hands)
+}
+
+// ThreeBet returns the percentage of chances to re-raise
+// an opening raise where the player did
+func (s *Stats) ThreeBet() float64 {
+	return percent(s.ThreeBets, s.ThreeBetChances)
+}
+
+// FoldToThreeBet returns the percentage of re-raised
+// opening raises the player folded
+func (s *Stats) FoldToThreeBet() float64 {
+	return percent(s.FoldsToThreeBet, s.FoldToThreeBetChances)
+}
+
+// CBet returns the percentage of chances to continuation
+// bet the flop where the player did
+func (s *Stats) CBet() float64 {
+	return percent(s.CBets, s.CBetChances)
+}
+
+// AF returns the aggression factor, bets and raises per call after the
+// flop. A player who never called has the number of bets and raises.
+func (s *Stats) AF() float64 {
+	if s.Calls == 0 {
+		return float64(s.Aggressive)
+	}
+	return float64(s.Aggressive) / float64(s.Calls)
+}
+
+// WTSD returns the percentage of flops seen that went to showdown
+func (s *Stats) WTSD() float64 {
+	return percent(s.Showdowns, s.SawFlop)
+}
+
+// WSD returns the percentage of showdowns where the player won chips
+func (s *Stats) WSD() float64 {
+	return percent(s.ShowdownsWon, s.Showdowns)
+}
+
+// BBPer100 returns the big blinds won per hundred hands
+func (s *Stats) BBPer100() float64 {
+	if s.Hands == 0 {
+		return 0
+	}
+	return 100 * s.BigBlinds / float64(s.Hands)
+}
+
+type key struct {
+	player   string
+	position string
+}
+
+// Aggregator collects statistics over many hands
+type Aggregator struct {
+	Filter Filter
+	stats  map[key]*Stats
+}
+
+// New creates an Aggregator counting the hands that pass a filter
+func New(f Filter) *Aggregator {
+	return &Aggregator{Filter: f, stats: make(map[key]*Stats)}
+}
+
+// Add counts a hand if it passes the filter
+func (a *Aggregator) Add(r *history.Record) {
+	if !a.Filter.Match(r) {
+		return
+	}
+	for i, c := range count(r) {
+		name := r.Players[i].Name
+		for _, k := range []key{{name, ""}, {name, r.Position(i)}} {
+			s, ok := a.stats[k]
+			if !ok {
+				s = &Stats{Player: k.player, Position: k.position}
+				a.stats[k] = s
+			}
+			s.add(c)
+		}
+	}
+}
+
+// Stats returns the statistics ordered by player, each player's
+// totals coming before their statistics by position
+func (a *Aggregator) Stats() []*Stats {
+	var stats []*Stats
+	for _, s := range a.stats {
+		stats = append(stats, s)
+	}
+	sort.Slice(stats, func(i, j int) bool {
+		if stats[i].Player != stats[j].Player {
+			return stats[i].Player < stats[j].Player
+		}
+		return stats[i].Position < stats[j].Position
+	})
+	return stats
+}
+
+// count works out each player's counts for a single hand
+func count(r *history.Record) []Counts {
+	n := len(r.Players)
+	counts := make([]Counts, n)
+	folded := make([]bool, n)
+	foldedPreflop := make([]bool, n)
+
+	// preflop the big blind is the first bet, the opener makes the
+	// second and a three bet is the first re-raise
+	raises, opener, aggressor := 0, -1, -1
+	threeBetChance := make([]bool, n)
+	foldChance := false
+	cbetChance := false
+	flopBet := false
+	for _, a := range r.Actions {
+		c := &counts[a.Seat]
+		if a.Type == game.Fold {
+			folded[a.Seat] = true
+		}
+		if a.Street == game.Preflop {
+			if raises == 1 && a.Seat != opener && !threeBetChance[a.Seat] {
+				threeBetChance[a.Seat] = true
+				c.ThreeBetChances++
+				if a.Type == game.Raise {
+					c.ThreeBets++
+				}
+			}
+			// the opener's next action faces the three bet, even
+			// if a four bet came in first
+			if raises >= 2 && a.Seat == opener && !foldChance {
+				foldChance = true
+				c.FoldToThreeBetChances++
+				if a.Type == game.Fold {
+					c.FoldsToThreeBet++
+				}
+			}
+			switch a.Type {
+			case game.Fold:
+				foldedPreflop[a.Seat] = true
+			case game.Call:
+				c.VPIP = 1
+			case game.Bet, game.Raise:
+				c.VPIP, c.PFR = 1, 1
+				raises++
+				if raises == 1 {
+					opener = a.Seat
+				}
+				aggressor = a.Seat
+			}
+			continue
+		}
+
+		if a.Street == game.Flop && !flopBet && a.Seat == aggressor && !cbetChance {
+			cbetChance = true
+			c.CBetChances++
+			if a.Type == game.Bet {
+				c.CBets++
+			}
+		}
+		switch a.Type {
+		case game.Bet, game.Raise:
+			c.Aggressive++
+			if a.Street == game.Flop {
+				flopBet = true
+			}
+		case game.Call:
+			c.Calls++
+		}
+	}
+
+	inHand := 0
+	for _, f := range folded {
+		if !f {
+			inHand++
+		}
+	}
+	for i := range counts {
+		c := &counts[i]
+		c.Hands = 1
+		if len(r.Board) >= 3 && !foldedPreflop[i] {
+			c.SawFlop = 1
+		}
+		if inHand > 1 && !folded[i] {
+			c.Showdowns = 1
+			if r.Players[i].Won > 0 {
+				c.ShowdownsWon = 1
+			}
+		}
+		if r.BigBlind > 0 {
+			c.BigBlinds = float64(r.Net(i)) / float64(r.BigBlind)
+		}
+	}
+	return counts
+}
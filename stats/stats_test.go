@@ -0,0 +1,217 @@
+package stats
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/aultimus/gosouth/deck"
+	"github.com/aultimus/gosouth/game"
+	"github.com/aultimus/gosouth/hand"
+	"github.com/aultimus/gosouth/history"
+	"github.com/stretchr/testify/assert"
+)
+
+func players(names ...string) []*history.Player {
+	var p []*history.Player
+	for i, n := range names {
+		p = append(p, &history.Player{Name: n, Seat: i + 1, Stack: 1000})
+	}
+	return p
+}
+
+func mkBoard() hand.Hand {
+	return hand.Hand(deck.New()[:5])
+}
+
+func action(seat int, street game.STREET, t game.ACTION, amount int) game.Action {
+	return game.Action{Seat: seat, Street: street, Type: t, Amount: amount}
+}
+
+// threeBet is a hand where the opener calls a three bet and
+// wins after the three bettor folds the river
+func threeBet() *history.Record {
+	r := &history.Record{
+		Time: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC), SmallBlind: 1, BigBlind: 2,
+		Button: 3, Players: players("ann", "bob", "cat", "dan"),
+		Board: mkBoard(),
+		Actions: []game.Action{
+			action(2, game.Preflop, game.Raise, 6),
+			action(3, game.Preflop, game.Raise, 18),
+			action(0, game.Preflop, game.Fold, 0),
+			action(1, game.Preflop, game.Call, 16),
+			action(2, game.Preflop, game.Call, 12),
+			action(1, game.Flop, game.Check, 0),
+			action(2, game.Flop, game.Check, 0),
+			action(3, game.Flop, game.Bet, 30),
+			action(1, game.Flop, game.Fold, 0),
+			action(2, game.Flop, game.Call, 30),
+			action(2, game.Turn, game.Check, 0),
+			action(3, game.Turn, game.Bet, 60),
+			action(2, game.Turn, game.Raise, 200),
+			action(3, game.Turn, game.Call, 140),
+			action(2, game.River, game.Bet, 100),
+			action(3, game.River, game.Fold, 0),
+		},
+	}
+	r.Players[0].Blind = 1
+	r.Players[1].Blind = 2
+	r.Players[2].Returned = 100
+	r.Players[2].Won = 515
+	return r
+}
+
+// checkedDown is a hand where the button raises, checks the
+// flop and loses at showdown
+func checkedDown() *history.Record {
+	r := &history.Record{
+		Time: time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC), SmallBlind: 1, BigBlind: 2,
+		Button: 0, Players: players("ann", "bob", "cat", "dan"),
+		Board: mkBoard(),
+		Actions: []game.Action{
+			action(3, game.Preflop, game.Fold, 0),
+			action(0, game.Preflop, game.Raise, 5),
+			action(1, game.Preflop, game.Fold, 0),
+			action(2, game.Preflop, game.Call, 3),
+			action(2, game.Flop, game.Check, 0),
+			action(0, game.Flop, game.Check, 0),
+			action(2, game.Turn, game.Bet, 10),
+			action(0, game.Turn, game.Call, 10),
+			action(2, game.River, game.Check, 0),
+			action(0, game.River, game.Check, 0),
+		},
+	}
+	r.Players[1].Blind = 1
+	r.Players[2].Blind = 2
+	r.Players[0].Won = 31
+	return r
+}
+
+// coldFourBet is a hand where the opener is three bet then four bet
+// by a player yet to act and folds
+func coldFourBet() *history.Record {
+	r := &history.Record{
+		Time: time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC), SmallBlind: 1, BigBlind: 2,
+		Button: 3, Players: players("ann", "bob", "cat", "dan"),
+		Actions: []game.Action{
+			action(2, game.Preflop, game.Raise, 6),
+			action(3, game.Preflop, game.Raise, 18),
+			action(0, game.Preflop, game.Raise, 50),
+			action(1, game.Preflop, game.Fold, 0),
+			action(2, game.Preflop, game.Fold, 0),
+			action(3, game.Preflop, game.Fold, 0),
+		},
+	}
+	r.Players[0].Blind = 1
+	r.Players[1].Blind = 2
+	r.Players[0].Returned = 32
+	r.Players[0].Won = 44
+	return r
+}
+
+func find(stats []*Stats, player, position string) *Stats {
+	for _, s := range stats {
+		if s.Player == player && s.Position == position {
+			return s
+		}
+	}
+	return nil
+}
+
+func TestStats(t *testing.T) {
+	a := assert.New(t)
+	agg := New(Filter{})
+	agg.Add(threeBet())
+	agg.Add(checkedDown())
+	stats := agg.Stats()
+
+	cat := find(stats, "cat", "")
+	a.Equal(Counts{Hands: 2, VPIP: 2, PFR: 1, ThreeBetChances: 1, FoldToThreeBetChances: 1,
+		Aggressive: 3, Calls: 1, SawFlop: 2, Showdowns: 1, BigBlinds: 126}, cat.Counts)
+	a.Equal(100.0, cat.VPIP())
+	a.Equal(50.0, cat.PFR())
+	a.Equal(0.0, cat.ThreeBet())
+	a.Equal(3.0, cat.AF())
+	a.Equal(50.0, cat.WTSD())
+	a.Equal(0.0, cat.WSD())
+	a.Equal(6300.0, cat.BBPer100())
+	a.Equal(1, find(stats, "cat", "UTG").Hands)
+	a.Equal(1, find(stats, "cat", "BB").Hands)
+
+	dan := find(stats, "dan", "")
+	a.Equal(50.0, dan.VPIP())
+	a.Equal(100.0, dan.ThreeBet())
+	a.Equal(100.0, dan.CBet())
+	a.Equal(2.0, dan.AF())
+	a.Equal(0.0, dan.WTSD())
+	a.Equal(-124.0, dan.BigBlinds)
+
+	ann := find(stats, "ann", "")
+	a.Equal(50.0, ann.PFR())
+	a.Equal(1, ann.CBetChances)
+	a.Equal(0.0, ann.CBet())
+	a.Equal(100.0, ann.WSD())
+	a.Equal(1, ann.Calls)
+	a.Equal(0.0, ann.AF())
+
+	bob := find(stats, "bob", "")
+	a.Equal(1, bob.ThreeBetChances)
+	a.Equal(0, bob.FoldToThreeBetChances)
+	a.Equal(1, bob.SawFlop)
+
+	// players come first, then their positions
+	a.Equal("ann", stats[0].Player)
+	a.Equal("", stats[0].Position)
+}
+
+func TestColdFourBet(t *testing.T) {
+	a := assert.New(t)
+	agg := New(Filter{})
+	agg.Add(coldFourBet())
+	stats := agg.Stats()
+
+	cat := find(stats, "cat", "")
+	a.Equal(1, cat.FoldToThreeBetChances)
+	a.Equal(100.0, cat.FoldToThreeBet())
+	dan := find(stats, "dan", "")
+	a.Equal(100.0, dan.ThreeBet())
+	// a four bet is not a three bet
+	ann := find(stats, "ann", "")
+	a.Equal(0, ann.ThreeBetChances)
+	a.Equal(0, ann.FoldToThreeBetChances)
+}
+
+func TestFilter(t *testing.T) {
+	a := assert.New(t)
+	high := threeBet()
+	high.SmallBlind, high.BigBlind = 5, 10
+	agg := New(Filter{BigBlind: 2, From: time.Date(2024, 1, 15, 0, 0, 0, 0, time.UTC)})
+	agg.Add(high)
+	agg.Add(threeBet())
+	agg.Add(checkedDown())
+	a.Equal(1, find(agg.Stats(), "cat", "").Hands)
+
+	f := Filter{To: time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC), Currency: "$"}
+	a.False(f.Match(threeBet()))
+	f.Currency = ""
+	a.True(f.Match(threeBet()))
+	a.False(f.Match(checkedDown()))
+}
+
+func TestWrite(t *testing.T) {
+	a := assert.New(t)
+	agg := New(Filter{})
+	agg.Add(threeBet())
+	stats := []*Stats{find(agg.Stats(), "cat", "UTG")}
+
+	var b bytes.Buffer
+	a.NoError(WriteCSV(&b, stats))
+	a.Equal("player,position,hands,vpip,pfr,three_bet,fold_to_three_bet,cbet,af,wtsd,wsd,bb_per_100\n"+
+		"cat,UTG,1,100.0,100.0,0.0,0.0,0.0,2.0,0.0,0.0,13350.0\n", b.String())
+
+	b.Reset()
+	a.NoError(WriteJSON(&b, stats))
+	a.JSONEq(`[{"player": "cat", "position": "UTG", "hands": 1, "vpip": 100, "pfr": 100,
+		"three_bet": 0, "fold_to_three_bet": 0, "cbet": 0, "af": 2, "wtsd": 0, "wsd": 0,
+		"bb_per_100": 13350}]`, b.String())
+}
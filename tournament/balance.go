@@ -0,0 +1,317 @@
+package tournament
+
+import (
+	"fmt"
+
+	"github.com/aultimus/gosouth/card"
+	"github.com/aultimus/gosouth/deck"
+)
+
+// EVENT is the type of an entry in the tournament log
+type EVENT int
+
+const (
+	// Seated constant, a player is drawn to a seat
+	Seated = EVENT(iota)
+	// Moved constant, a player moves to another table
+	Moved = EVENT(iota)
+	// Eliminated constant
+	Eliminated = EVENT(iota)
+	// Won constant, the last player left
+	Won = EVENT(iota)
+	// Rebought constant
+	Rebought = EVENT(iota)
+	// AddedOn constant
+	AddedOn = EVENT(iota)
+	// LevelUp constant, a new level starts
+	LevelUp = EVENT(iota)
+	// TableBroken constant, a table's players are moved to other tables
+	TableBroken = EVENT(iota)
+	// FinalTable constant, the remaining players are redrawn to one table
+	FinalTable = EVENT(iota)
+)
+
+var eventNames = []string{"seated", "moved", "eliminated", "won", "rebought",
+	"added on", "level up", "table broken", "final table"}
+
+func (e EVENT) String() string {
+	return eventNames[e]
+}
+
+// Event is an entry in the tournament log. Hand is the number of hands
+// finished before the event, Player is -1 for events about tables and levels.
+type Event struct {
+	Hand      int
+	Type      EVENT
+	Player    int
+	Name      string
+	Table     int
+	Seat      int
+	FromTable int
+	FromSeat  int
+	Place     int
+	Level     int
+}
+
+func (e Event) String() string {
+	switch e.Type {
+	case Seated:
+		return fmt.Sprintf("hand %d: %s seated at table %d seat %d", e.Hand, e.Name, e.Table+1, e.Seat+1)
+	case Moved:
+		return fmt.Sprintf("hand %d: %s moved from table %d seat %d to table %d seat %d",
+			e.Hand, e.Name, e.FromTable+1, e.FromSeat+1, e.Table+1, e.Seat+1)
+	case Eliminated, Won:
+		return fmt.Sprintf("hand %d: %s %s in place %d", e.Hand, e.Name, e.Type, e.Place)
+	case LevelUp:
+		return fmt.Sprintf("hand %d: level %d starts", e.Hand, e.Level+1)
+	case TableBroken, FinalTable:
+		return fmt.Sprintf("hand %d: %s at table %d", e.Hand, e.Type, e.Table+1)
+	}
+	return fmt.Sprintf("hand %d: %s %s", e.Hand, e.Name, e.Type)
+}
+
+func (t *Tournament) shuffler() deck.Shuffler {
+	if t.Config.Shuffler == nil {
+		return deck.Crypto
+	}
+	return t.Config.Shuffler
+}
+
+func (t *Tournament) log(e Event) {
+	e.Hand = t.played
+	if e.Player >= 0 {
+		e.Name = t.Players[e.Player].Name
+	}
+	t.Events = append(t.Events, e)
+}
+
+func (t *Tournament) index(p *Player) int {
+	for i, q := range t.Players {
+		if q == p {
+			return i
+		}
+	}
+	panic("player is not in the tournament")
+}
+
+// move seats a player in an empty seat
+func (t *Tournament) move(p, table, seat int) {
+	pl := t.Players[p]
+	t.Tables[pl.Table].Seats[pl.Seat] = -1
+	t.Tables[table].Seats[seat] = p
+	t.log(Event{Type: Moved, Player: p, FromTable: pl.Table, FromSeat: pl.Seat, Table: table, Seat: seat})
+	pl.Table, pl.Seat = table, seat
+}
+
+// shuffled returns 0 to n-1 in the order cards standing
+// for them come off a shuffled deck
+func (t *Tournament) shuffled(n int) []int {
+	var d deck.Deck
+	index := make(map[*card.Card]int)
+	for len(d) < n {
+		for _, c := range deck.New()[:min(n-len(d), 52)] {
+			index[c] = len(d)
+			d = append(d, c)
+		}
+	}
+	order := make([]int, n)
+	for i, c := range t.shuffler().Shuffle(d) {
+		order[i] = index[c]
+	}
+	return order
+}
+
+// Draw reseats every player at random, keeping the number of players
+// at each table. It can only be used before the first hand is dealt.
+func (t *Tournament) Draw() error {
+	if t.played > 0 {
+		return fmt.Errorf("seats cannot be drawn once hands have been played")
+	}
+	var seats [][2]int
+	for i, tb := range t.Tables {
+		if tb.hand != nil {
+			return fmt.Errorf("table %d has a hand in progress", i)
+		}
+		for s, p := range tb.Seats {
+			if p != -1 {
+				seats = append(seats, [2]int{i, s})
+			}
+		}
+	}
+	for i, p := range t.shuffled(len(seats)) {
+		table, seat := seats[i][0], seats[i][1]
+		t.Tables[table].Seats[seat] = p
+		t.Players[p].Table, t.Players[p].Seat = table, seat
+		t.log(Event{Type: Seated, Player: p, Table: table, Seat: seat})
+	}
+	return nil
+}
+
+// seated returns the players at a table
+func (t *Tournament) seated(table int) []int {
+	var players []int
+	for _, p := range t.Tables[table].Seats {
+		if p != -1 {
+			players = append(players, p)
+		}
+	}
+	return players
+}
+
+// open returns the tables that have not been broken
+func (t *Tournament) open() []int {
+	var tables []int
+	for i, tb := range t.Tables {
+		if !tb.Broken {
+			tables = append(tables, i)
+		}
+	}
+	return tables
+}
+
+// bigBlind returns the seat due the big blind next hand at a table,
+// or -1 if fewer than two players there have chips
+func (t *Tournament) bigBlind(tb *Table) int {
+	seats, button := t.dealt(tb)
+	switch len(seats) {
+	case 0, 1:
+		return -1
+	case 2:
+		return seats[(button+1)%2]
+	}
+	return seats[(button+2)%len(seats)]
+}
+
+// balance breaks tables once the players left fit around fewer of them
+// and evens out the rest. Players are only taken from tables that are not
+// playing a hand, a table playing one is dealt with after it finishes.
+func (t *Tournament) balance() {
+	if t.Done() {
+		return
+	}
+	open := t.open()
+	if len(open) > 1 && t.Remaining() <= t.Config.TableSize {
+		t.finalTable(open)
+		return
+	}
+	for len(open) > 2 && t.Remaining() <= (len(open)-1)*t.Config.TableSize && t.breakTable(open) {
+		open = t.open()
+	}
+	for t.balanceOnce(open) {
+	}
+}
+
+// finalTable redraws every remaining player to the first open table
+// once none of the tables are playing a hand
+func (t *Tournament) finalTable(open []int) {
+	var players []int
+	for _, i := range open {
+		if t.Tables[i].hand != nil {
+			return
+		}
+		players = append(players, t.seated(i)...)
+	}
+	final := open[0]
+	for _, i := range open {
+		tb := t.Tables[i]
+		for s := range tb.Seats {
+			tb.Seats[s] = -1
+		}
+		if i != final {
+			tb.Broken = true
+			t.log(Event{Type: TableBroken, Player: -1, Table: i})
+		}
+	}
+	t.log(Event{Type: FinalTable, Player: -1, Table: final})
+	tb := t.Tables[final]
+	seats := t.shuffled(t.Config.TableSize)
+	for i, p := range players {
+		tb.Seats[seats[i]] = p
+		t.Players[p].Table, t.Players[p].Seat = final, seats[i]
+		t.log(Event{Type: Seated, Player: p, Table: final, Seat: seats[i]})
+	}
+	// the button starts with the first player drawn
+	tb.Button = seats[0]
+}
+
+// breakTable breaks the open table with the fewest players, the last
+// such table if there is a tie. Its players are drawn in random order
+// to random empty seats at the tables with the fewest players.
+func (t *Tournament) breakTable(open []int) bool {
+	broken := open[0]
+	for _, i := range open {
+		if len(t.seated(i)) <= len(t.seated(broken)) {
+			broken = i
+		}
+	}
+	if t.Tables[broken].hand != nil {
+		return false
+	}
+	t.Tables[broken].Broken = true
+	t.log(Event{Type: TableBroken, Player: -1, Table: broken})
+	players := t.seated(broken)
+	for _, i := range t.shuffled(len(players)) {
+		to := -1
+		for _, j := range open {
+			if j != broken && (to == -1 || len(t.seated(j)) < len(t.seated(to))) {
+				to = j
+			}
+		}
+		t.move(players[i], to, t.randomSeat(to))
+	}
+	return true
+}
+
+// randomSeat draws an empty seat at a table
+func (t *Tournament) randomSeat(table int) int {
+	tb := t.Tables[table]
+	for _, s := range t.shuffled(len(tb.Seats)) {
+		if tb.Seats[s] == -1 {
+			return s
+		}
+	}
+	panic(fmt.Sprintf("table %d is full", table))
+}
+
+// balanceOnce moves a player from the table with the most players to the
+// one with the fewest if they differ by two or more, passing over tables
+// playing a hand. The player due the big blind moves to the empty seat
+// that will next take the big blind.
+func (t *Tournament) balanceOnce(open []int) bool {
+	most, fewest := -1, open[0]
+	for _, i := range open {
+		n := len(t.seated(i))
+		if t.Tables[i].hand == nil && (most == -1 || n > len(t.seated(most))) {
+			most = i
+		}
+		if n < len(t.seated(fewest)) {
+			fewest = i
+		}
+	}
+	if most == -1 || len(t.seated(most))-len(t.seated(fewest)) < 2 {
+		return false
+	}
+	from := t.Tables[most]
+	seat := t.bigBlind(from)
+	if seat == -1 {
+		// nobody there has chips to post, move the first player seated
+		seat = 0
+		for from.Seats[seat] == -1 {
+			seat++
+		}
+	}
+
+	to := t.Tables[fewest]
+	empty := t.randomSeat(fewest)
+	if bb := t.bigBlind(to); bb != -1 {
+		// the first empty seat after the big blind is next to take it
+		for s := 1; s < len(to.Seats); s++ {
+			if next := (bb + s) % len(to.Seats); to.Seats[next] == -1 {
+				empty = next
+				break
+			}
+		}
+	}
+	t.move(from.Seats[seat], fewest, empty)
+	return true
+}
@@ -0,0 +1,533 @@
+package tournament
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/aultimus/gosouth/deck"
+	"github.com/aultimus/gosouth/game"
+)
+
+// Level is a stage of the blind schedule. It lasts for Duration when the
+// tournament is played by time and for Hands hands when it is played by
+// hands. A break is a level without hands that always lasts for Duration.
+type Level struct {
+	SmallBlind   int
+	BigBlind     int
+	Ante         int
+	BigBlindAnte int
+	Duration     time.Duration
+	Hands        int
+	Break        bool
+}
+
+func (l Level) String() string {
+	if l.Break {
+		return fmt.Sprintf("break %s", l.Duration)
+	}
+	s := fmt.Sprintf("%d/%d", l.SmallBlind, l.BigBlind)
+	if l.Ante > 0 {
+		s += fmt.Sprintf(" ante %d", l.Ante)
+	}
+	if l.BigBlindAnte > 0 {
+		s += fmt.Sprintf(" bb ante %d", l.BigBlindAnte)
+	}
+	return s
+}
+
+// Rebuy allows a player to buy Chips for Cost while they have at most
+// Below chips, up to Max times during the first Levels levels
+type Rebuy struct {
+	Cost   int
+	Chips  int
+	Below  int
+	Max    int
+	Levels int
+}
+
+// AddOn allows every player to buy Chips for Cost once during Level
+type AddOn struct {
+	Cost  int
+	Chips int
+	Level int
+}
+
+// Config describes a tournament. The last level of the schedule lasts
+// until the tournament is over.
+type Config struct {
+	Schedule []Level
+	// ByHands plays levels by the number of hands dealt
+	// over all tables rather than by time
+	ByHands   bool
+	BuyIn     int
+	Stack     int
+	TableSize int
+	Structure game.Structure
+	// Shuffler draws seats, deck.Crypto if nil
+	Shuffler deck.Shuffler
+	Rebuy    Rebuy
+	AddOn    AddOn
+	// Payouts are the percentage of the prize pool paid to each place,
+	// first place first
+	Payouts []float64
+}
+
+// Player is an entrant, Place is zero until they are eliminated
+// or have won
+type Player struct {
+	Name   string
+	Stack  int
+	Table  int
+	Seat   int
+	Rebuys int
+	AddOn  bool
+	Place  int
+	// busted orders players waiting on a rebuy
+	busted int
+}
+
+// Table is a table of the tournament, Seats holds the index of the
+// player in each seat or -1 if it is empty
+type Table struct {
+	Seats  []int
+	Button int
+	Broken bool
+	// hand holds the player in each seat of the game in progress
+	hand []int
+}
+
+// Result is a player's finishing place and prize
+type Result struct {
+	Player *Player
+	Place  int
+	Prize  int
+}
+
+// Tournament runs a tournament over one or more tables, dealing hands
+// with the game engine and tracking levels, rebuys and eliminations
+type Tournament struct {
+	Config  Config
+	Players []*Player
+	Tables  []*Table
+	// Level is the index of the current level in the schedule
+	Level int
+	// Elapsed is the time spent in the current level
+	Elapsed time.Duration
+	// Hands counts the hands finished in the current level
+	Hands int
+	// Events logs seating, moves, eliminations and levels in order
+	Events []Event
+
+	pool   int
+	busted int
+	played int
+}
+
+// New seats the players in order around as few tables as will hold them,
+// spreading them evenly
+func New(cfg Config, names []string) (*Tournament, error) {
+	if len(names) < 2 {
+		return nil, fmt.Errorf("a tournament needs at least 2 players, not %d", len(names))
+	}
+	if len(cfg.Schedule) == 0 {
+		return nil, fmt.Errorf("a tournament needs a schedule")
+	}
+	for i, l := range cfg.Schedule {
+		switch {
+		case l.Break && l.Duration <= 0:
+			return nil, fmt.Errorf("break at level %d has no duration", i+1)
+		case !l.Break && l.BigBlind <= 0:
+			return nil, fmt.Errorf("level %d has no big blind", i+1)
+		case !l.Break && i < len(cfg.Schedule)-1 && cfg.ByHands && l.Hands <= 0:
+			return nil, fmt.Errorf("level %d has no hands", i+1)
+		case !l.Break && i < len(cfg.Schedule)-1 && !cfg.ByHands && l.Duration <= 0:
+			return nil, fmt.Errorf("level %d has no duration", i+1)
+		}
+	}
+	if cfg.Schedule[len(cfg.Schedule)-1].Break {
+		return nil, fmt.Errorf("the schedule cannot end with a break")
+	}
+	if cfg.Stack <= 0 {
+		return nil, fmt.Errorf("starting stack %d has no chips", cfg.Stack)
+	}
+	if cfg.TableSize < 2 || cfg.TableSize > 10 {
+		return nil, fmt.Errorf("tables seat 2 to 10 players, not %d", cfg.TableSize)
+	}
+	total := 0.0
+	for _, p := range cfg.Payouts {
+		if p < 0 {
+			return nil, fmt.Errorf("payout %v is negative", p)
+		}
+		total += p
+	}
+	if total > 100 {
+		return nil, fmt.Errorf("payouts total %v%%", total)
+	}
+
+	t := &Tournament{Config: cfg, pool: cfg.BuyIn * len(names)}
+	numTables := (len(names) + cfg.TableSize - 1) / cfg.TableSize
+	for i := 0; i < numTables; i++ {
+		tb := &Table{Seats: make([]int, cfg.TableSize)}
+		for s := range tb.Seats {
+			tb.Seats[s] = -1
+		}
+		t.Tables = append(t.Tables, tb)
+	}
+	for i, name := range names {
+		tb, seat := i%numTables, i/numTables
+		t.Players = append(t.Players, &Player{Name: name, Stack: cfg.Stack, Table: tb, Seat: seat})
+		t.Tables[tb].Seats[seat] = i
+		t.log(Event{Type: Seated, Player: i, Table: tb, Seat: seat})
+	}
+	return t, nil
+}
+
+// CurrentLevel returns the level being played
+func (t *Tournament) CurrentLevel() Level {
+	return t.Config.Schedule[t.Level]
+}
+
+// PrizePool returns the buy-ins, rebuys and add-ons paid in
+func (t *Tournament) PrizePool() int {
+	return t.pool
+}
+
+// Remaining returns the number of players not yet eliminated
+func (t *Tournament) Remaining() int {
+	n := 0
+	for _, p := range t.Players {
+		if p.Place == 0 {
+			n++
+		}
+	}
+	return n
+}
+
+// Done returns true once a single player is left
+func (t *Tournament) Done() bool {
+	return t.Remaining() <= 1
+}
+
+// Advance moves the clock on. Levels played by time, and breaks,
+// end once their duration has passed.
+func (t *Tournament) Advance(d time.Duration) {
+	t.Elapsed += d
+	for {
+		l := t.CurrentLevel()
+		if t.Level == len(t.Config.Schedule)-1 || (t.Config.ByHands && !l.Break) || t.Elapsed < l.Duration {
+			break
+		}
+		t.Elapsed -= l.Duration
+		t.nextLevel()
+	}
+	t.balance()
+}
+
+// nextLevel moves to the next level of the schedule, players still
+// waiting on a rebuy are eliminated once rebuys are over
+func (t *Tournament) nextLevel() {
+	t.Level++
+	t.Hands = 0
+	t.log(Event{Type: LevelUp, Player: -1, Level: t.Level})
+	var out []*Player
+	for _, p := range t.Players {
+		if p.Place == 0 && p.Stack == 0 && !t.canRebuy(p) {
+			out = append(out, p)
+		}
+	}
+	sort.Slice(out, func(i, j int) bool {
+		return out[i].busted < out[j].busted
+	})
+	t.eliminate(out)
+}
+
+// inHand returns true if a player is in a hand being played
+func (t *Tournament) inHand(p int) bool {
+	tb := t.Tables[t.Players[p].Table]
+	for _, q := range tb.hand {
+		if q == p {
+			return true
+		}
+	}
+	return false
+}
+
+func (t *Tournament) player(p int) (*Player, error) {
+	if p < 0 || p >= len(t.Players) {
+		return nil, fmt.Errorf("player %d is not in the tournament", p)
+	}
+	pl := t.Players[p]
+	if pl.Place != 0 {
+		return nil, fmt.Errorf("%s is out of the tournament", pl.Name)
+	}
+	if t.inHand(p) {
+		return nil, fmt.Errorf("%s is in a hand", pl.Name)
+	}
+	return pl, nil
+}
+
+func (t *Tournament) canRebuy(p *Player) bool {
+	r := t.Config.Rebuy
+	return p.Rebuys < r.Max && t.Level < r.Levels && p.Stack <= r.Below
+}
+
+// Rebuy buys a player more chips
+func (t *Tournament) Rebuy(p int) error {
+	pl, err := t.player(p)
+	if err != nil {
+		return err
+	}
+	if !t.canRebuy(pl) {
+		return fmt.Errorf("%s cannot rebuy", pl.Name)
+	}
+	pl.Rebuys++
+	pl.Stack += t.Config.Rebuy.Chips
+	t.pool += t.Config.Rebuy.Cost
+	t.log(Event{Type: Rebought, Player: p, Table: pl.Table, Seat: pl.Seat})
+	return nil
+}
+
+// AddOn buys a player the add-on
+func (t *Tournament) AddOn(p int) error {
+	pl, err := t.player(p)
+	if err != nil {
+		return err
+	}
+	if t.Config.AddOn.Chips <= 0 || t.Level != t.Config.AddOn.Level || pl.AddOn {
+		return fmt.Errorf("%s cannot take the add-on", pl.Name)
+	}
+	pl.AddOn = true
+	pl.Stack += t.Config.AddOn.Chips
+	t.pool += t.Config.AddOn.Cost
+	t.log(Event{Type: AddedOn, Player: p, Table: pl.Table, Seat: pl.Seat})
+	return nil
+}
+
+// Decline eliminates a busted player who will not rebuy
+func (t *Tournament) Decline(p int) error {
+	pl, err := t.player(p)
+	if err != nil {
+		return err
+	}
+	if pl.Stack > 0 {
+		return fmt.Errorf("%s has chips", pl.Name)
+	}
+	t.eliminate([]*Player{pl})
+	t.balance()
+	return nil
+}
+
+// Move seats a player in an empty seat at another table, tables
+// are broken and balanced as players bust without calling it
+func (t *Tournament) Move(p, table, seat int) error {
+	if _, err := t.player(p); err != nil {
+		return err
+	}
+	if table < 0 || table >= len(t.Tables) || seat < 0 || seat >= t.Config.TableSize {
+		return fmt.Errorf("table %d seat %d does not exist", table, seat)
+	}
+	to := t.Tables[table]
+	if to.Broken || to.Seats[seat] != -1 {
+		return fmt.Errorf("table %d seat %d is not free", table, seat)
+	}
+	t.move(p, table, seat)
+	return nil
+}
+
+// eliminate removes players from the tournament, the first
+// player given finishes lowest
+func (t *Tournament) eliminate(out []*Player) {
+	place := t.Remaining()
+	for _, p := range out {
+		p.Place = place
+		place--
+		t.Tables[p.Table].Seats[p.Seat] = -1
+		t.log(Event{Type: Eliminated, Player: t.index(p), Table: p.Table, Seat: p.Seat, Place: p.Place})
+	}
+	if place == 1 {
+		for i, p := range t.Players {
+			if p.Place == 0 {
+				p.Place = 1
+				t.log(Event{Type: Won, Player: i, Table: p.Table, Seat: p.Seat, Place: 1})
+			}
+		}
+	}
+}
+
+// Deal starts a hand at a table between the seated players with chips
+func (t *Tournament) Deal(table int, d deck.Deck) (*game.Game, error) {
+	if table < 0 || table >= len(t.Tables) {
+		return nil, fmt.Errorf("table %d does not exist", table)
+	}
+	if t.Done() {
+		return nil, fmt.Errorf("the tournament is over")
+	}
+	l := t.CurrentLevel()
+	if l.Break {
+		return nil, fmt.Errorf("level %d is a break", t.Level+1)
+	}
+	tb := t.Tables[table]
+	if tb.Broken {
+		return nil, fmt.Errorf("table %d has been broken", table)
+	}
+	if tb.hand != nil {
+		return nil, fmt.Errorf("table %d has a hand in progress", table)
+	}
+
+	seats, button := t.dealt(tb)
+	if len(seats) < 2 {
+		return nil, fmt.Errorf("table %d has fewer than 2 players with chips", table)
+	}
+	var stacks []int
+	for _, s := range seats {
+		stacks = append(stacks, t.Players[tb.Seats[s]].Stack)
+	}
+	cfg := game.Config{
+		SmallBlind:   l.SmallBlind,
+		BigBlind:     l.BigBlind,
+		Ante:         l.Ante,
+		BigBlindAnte: l.BigBlindAnte,
+		Structure:    t.Config.Structure,
+	}
+	g, err := game.New(cfg, stacks, button, d)
+	if err != nil {
+		return nil, err
+	}
+	tb.Button = seats[button]
+	for _, s := range seats {
+		tb.hand = append(tb.hand, tb.Seats[s])
+	}
+	return g, nil
+}
+
+// Finish records the result of a complete hand dealt at a table. Players
+// left without chips wait on a rebuy if they can make one, otherwise they
+// are eliminated, those who started the hand with fewer chips first.
+func (t *Tournament) Finish(table int, g *game.Game) error {
+	if table < 0 || table >= len(t.Tables) {
+		return fmt.Errorf("table %d does not exist", table)
+	}
+	tb := t.Tables[table]
+	if tb.hand == nil {
+		return fmt.Errorf("table %d has no hand in progress", table)
+	}
+	if !g.Done() || len(g.Seats) != len(tb.hand) {
+		return fmt.Errorf("game is not a complete hand from table %d", table)
+	}
+
+	type bust struct {
+		p     *Player
+		start int
+	}
+	var busts []bust
+	for i, p := range tb.hand {
+		pl := t.Players[p]
+		start := pl.Stack
+		pl.Stack = g.Seats[i].Stack
+		if pl.Stack == 0 {
+			busts = append(busts, bust{pl, start})
+		}
+	}
+	tb.hand = nil
+	sort.SliceStable(busts, func(i, j int) bool {
+		return busts[i].start < busts[j].start
+	})
+	var out []*Player
+	for _, b := range busts {
+		t.busted++
+		b.p.busted = t.busted
+		if !t.canRebuy(b.p) {
+			out = append(out, b.p)
+		}
+	}
+	t.eliminate(out)
+
+	// the button moves to the next occupied seat
+	for s := 1; s <= len(tb.Seats); s++ {
+		next := (tb.Button + s) % len(tb.Seats)
+		if tb.Seats[next] != -1 {
+			tb.Button = next
+			break
+		}
+	}
+
+	t.Hands++
+	t.played++
+	if l := t.CurrentLevel(); t.Config.ByHands && t.Level < len(t.Config.Schedule)-1 && t.Hands >= l.Hands {
+		t.Elapsed = 0
+		t.nextLevel()
+	}
+	t.balance()
+	return nil
+}
+
+// dealt returns the seats dealt into the next hand at a table, those
+// seating a player with chips, and the index of the button among them.
+// The button is the last of them at or before the button seat.
+func (t *Tournament) dealt(tb *Table) ([]int, int) {
+	var seats []int
+	button := -1
+	for s, p := range tb.Seats {
+		if p == -1 || t.Players[p].Stack == 0 {
+			continue
+		}
+		if s <= tb.Button {
+			button = len(seats)
+		}
+		seats = append(seats, s)
+	}
+	if button == -1 {
+		button = len(seats) - 1
+	}
+	return seats, button
+}
+
+// Play deals a hand at a table and plays it out with decide
+// choosing the action for each seat to act
+func (t *Tournament) Play(table int, d deck.Deck, decide func(g *game.Game) game.Action) (*game.Game, error) {
+	g, err := t.Deal(table, d)
+	if err != nil {
+		return nil, err
+	}
+	for !g.Done() {
+		if err := g.Act(decide(g)); err != nil {
+			t.Tables[table].hand = nil
+			return nil, err
+		}
+	}
+	return g, t.Finish(table, g)
+}
+
+// Results returns the players who have finished in order of place, with
+// their prize. The prize pool is split by the payout percentages, chips
+// lost to rounding go to first place.
+func (t *Tournament) Results() []Result {
+	prizes := make([]int, len(t.Config.Payouts))
+	paid, total := 0, 0.0
+	for i, pct := range t.Config.Payouts {
+		prizes[i] = int(float64(t.pool) * pct / 100)
+		paid += prizes[i]
+		total += pct
+	}
+	if len(prizes) > 0 {
+		prizes[0] += int(math.Round(float64(t.pool)*total/100)) - paid
+	}
+
+	var results []Result
+	for _, p := range t.Players {
+		if p.Place == 0 {
+			continue
+		}
+		r := Result{Player: p, Place: p.Place}
+		if p.Place <= len(prizes) {
+			r.Prize = prizes[p.Place-1]
+		}
+		results = append(results, r)
+	}
+	sort.Slice(results, func(i, j int) bool {
+		return results[i].Place < results[j].Place
+	})
+	return results
+}
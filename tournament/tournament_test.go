@@ -0,0 +1,429 @@
+package tournament
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/aultimus/gosouth/deck"
+	"github.com/aultimus/gosouth/game"
+	"github.com/stretchr/testify/assert"
+)
+
+var schedule = []Level{
+	{SmallBlind: 1, BigBlind: 2, Duration: 20 * time.Minute, Hands: 2},
+	{SmallBlind: 2, BigBlind: 4, BigBlindAnte: 4, Duration: 20 * time.Minute, Hands: 2},
+	{Break: true, Duration: 10 * time.Minute},
+	{SmallBlind: 5, BigBlind: 10, Ante: 1},
+}
+
+func config() Config {
+	return Config{
+		Schedule:  schedule,
+		BuyIn:     100,
+		Stack:     100,
+		TableSize: 3,
+		Payouts:   []float64{50, 30, 20},
+	}
+}
+
+// passive checks or folds
+func passive(g *game.Game) game.Action {
+	for _, l := range g.LegalActions() {
+		if l.Type == game.Check {
+			return game.Action{Seat: g.ToAct(), Type: game.Check}
+		}
+	}
+	return game.Action{Seat: g.ToAct(), Type: game.Fold}
+}
+
+// shove moves all in or calls
+func shove(g *game.Game) game.Action {
+	legal := g.LegalActions()
+	l := legal[len(legal)-1]
+	if l.Type == game.Bet || l.Type == game.Raise {
+		return game.Action{Seat: g.ToAct(), Type: l.Type, Amount: l.Max}
+	}
+	for _, l := range legal {
+		if l.Type == game.Call {
+			return game.Action{Seat: g.ToAct(), Type: game.Call}
+		}
+	}
+	return game.Action{Seat: g.ToAct(), Type: game.Check}
+}
+
+// mixed returns a deck that does not deal a flush to everyone
+func mixed() deck.Deck {
+	d := deck.New()
+	m := make(deck.Deck, len(d))
+	for i := range d {
+		m[i] = d[i*5%len(d)]
+	}
+	return m
+}
+
+func seated(tb *Table) []int {
+	var players []int
+	for _, p := range tb.Seats {
+		if p != -1 {
+			players = append(players, p)
+		}
+	}
+	return players
+}
+
+func emptySeat(tb *Table) int {
+	for s, p := range tb.Seats {
+		if p == -1 {
+			return s
+		}
+	}
+	return -1
+}
+
+func TestNew(t *testing.T) {
+	a := assert.New(t)
+	tn, err := New(config(), []string{"ann", "bob", "cat", "dan", "eve"})
+	a.NoError(err)
+	a.Equal(2, len(tn.Tables))
+	a.Equal([]int{0, 2, 4}, tn.Tables[0].Seats)
+	a.Equal([]int{1, 3, -1}, tn.Tables[1].Seats)
+	a.Equal(500, tn.PrizePool())
+	a.Equal(5, tn.Remaining())
+	a.Equal("2/4 bb ante 4", schedule[1].String())
+	a.Equal("break 10m0s", schedule[2].String())
+
+	for _, c := range []func(c *Config){
+		func(c *Config) { c.Schedule = nil },
+		func(c *Config) { c.Schedule = []Level{{BigBlind: 2, Duration: time.Minute}, {Break: true}} },
+		func(c *Config) { c.Schedule = []Level{{BigBlind: 2}, {BigBlind: 4}} },
+		func(c *Config) { c.Schedule = []Level{{Duration: time.Minute}} },
+		func(c *Config) { c.Stack = 0 },
+		func(c *Config) { c.TableSize = 11 },
+		func(c *Config) { c.Payouts = []float64{60, 50} },
+	} {
+		cfg := config()
+		c(&cfg)
+		_, err := New(cfg, []string{"ann", "bob"})
+		a.Error(err)
+	}
+	_, err = New(config(), []string{"ann"})
+	a.Error(err)
+}
+
+func TestLevelsByTime(t *testing.T) {
+	a := assert.New(t)
+	tn, err := New(config(), []string{"ann", "bob"})
+	a.NoError(err)
+	tn.Advance(25 * time.Minute)
+	a.Equal(1, tn.Level)
+	a.Equal(5*time.Minute, tn.Elapsed)
+	g, err := tn.Deal(0, deck.New())
+	a.NoError(err)
+	a.Equal(4, g.Config.BigBlindAnte)
+	a.Error(tn.Finish(0, g))
+	for !g.Done() {
+		a.NoError(g.Act(passive(g)))
+	}
+	a.NoError(tn.Finish(0, g))
+
+	tn.Advance(20 * time.Minute)
+	a.True(tn.CurrentLevel().Break)
+	_, err = tn.Deal(0, deck.New())
+	a.Error(err)
+	// the last level never ends
+	tn.Advance(5 * time.Hour)
+	a.Equal(3, tn.Level)
+}
+
+func TestLevelsByHands(t *testing.T) {
+	a := assert.New(t)
+	cfg := config()
+	cfg.ByHands = true
+	tn, err := New(cfg, []string{"ann", "bob", "cat"})
+	a.NoError(err)
+	tn.Advance(time.Hour)
+	a.Equal(0, tn.Level)
+
+	g, err := tn.Play(0, deck.New(), passive)
+	a.NoError(err)
+	// ann has the button, bob the small blind and cat the big blind
+	a.Equal([]int{0, 0, 2}, g.Winnings)
+	a.Equal([]int{100, 99, 101}, []int{tn.Players[0].Stack, tn.Players[1].Stack, tn.Players[2].Stack})
+	a.Equal(1, tn.Tables[0].Button)
+
+	_, err = tn.Play(0, deck.New(), passive)
+	a.NoError(err)
+	a.Equal(1, tn.Level)
+	a.Equal(0, tn.Hands)
+
+	g, err = tn.Play(0, deck.New(), passive)
+	a.NoError(err)
+	// bob has the big blind and posts the ante for the table
+	a.Equal([]int{0, 8, 0}, g.Winnings)
+	_, err = tn.Play(0, deck.New(), passive)
+	a.NoError(err)
+	a.True(tn.CurrentLevel().Break)
+	_, err = tn.Deal(0, deck.New())
+	a.Error(err)
+	tn.Advance(10 * time.Minute)
+	a.Equal(3, tn.Level)
+	_, err = tn.Deal(0, deck.New())
+	a.NoError(err)
+	_, err = tn.Deal(0, deck.New())
+	a.Error(err)
+}
+
+func TestTournament(t *testing.T) {
+	a := assert.New(t)
+	cfg := config()
+	names := []string{"ann", "bob", "cat", "dan", "eve", "fay"}
+	tn, err := New(cfg, names)
+	a.NoError(err)
+	a.Equal(2, len(tn.Tables))
+
+	d := mixed()
+	for hands := 0; !tn.Done(); hands++ {
+		a.True(hands < 100)
+		for table := range tn.Tables {
+			if _, err := tn.Play(table, d, shove); err == nil {
+				// rotate the deck so hands differ
+				d = append(d[7:], d[:7]...)
+			}
+		}
+	}
+
+	results := tn.Results()
+	a.Equal(6, len(results))
+	total := 0
+	for i, r := range results {
+		a.Equal(i+1, r.Place)
+		total += r.Prize
+	}
+	a.Equal(600, total)
+	a.Equal(300, results[0].Prize)
+	a.Equal(0, results[3].Prize)
+	a.Equal(600, results[0].Player.Stack)
+	_, err = tn.Deal(0, d)
+	a.Error(err)
+
+	// the players left were brought together at a final table
+	var final, eliminated int
+	for _, e := range tn.Events {
+		switch e.Type {
+		case FinalTable:
+			final++
+		case Eliminated:
+			eliminated++
+		}
+	}
+	a.Equal(1, final)
+	a.Equal(5, eliminated)
+	a.Equal(Won, tn.Events[len(tn.Events)-1].Type)
+}
+
+func TestDraw(t *testing.T) {
+	a := assert.New(t)
+	tn, err := New(config(), []string{"ann", "bob", "cat", "dan", "eve"})
+	a.NoError(err)
+	a.NoError(tn.Draw())
+	a.Equal(3, len(seated(tn.Tables[0])))
+	a.Equal(2, len(seated(tn.Tables[1])))
+	for i, p := range tn.Players {
+		a.Equal(i, tn.Tables[p.Table].Seats[p.Seat])
+	}
+	a.Equal(10, len(tn.Events))
+	a.Equal(Seated, tn.Events[9].Type)
+
+	_, err = tn.Play(0, mixed(), passive)
+	a.NoError(err)
+	a.Error(tn.Draw())
+
+	// draws with the same seed are the same
+	var events [][]Event
+	for i := 0; i < 2; i++ {
+		cfg := config()
+		cfg.Shuffler = deck.NewSeeded(7)
+		tn, err := New(cfg, []string{"ann", "bob", "cat", "dan", "eve"})
+		a.NoError(err)
+		a.NoError(tn.Draw())
+		events = append(events, tn.Events)
+	}
+	a.Equal(events[0], events[1])
+}
+
+func TestBalance(t *testing.T) {
+	a := assert.New(t)
+	cfg := config()
+	cfg.TableSize = 4
+	names := []string{"ann", "bob", "cat", "dan", "eve", "fay", "gus", "hal", "ivy"}
+	tn, err := New(cfg, names)
+	a.NoError(err)
+	a.Equal(3, len(tn.Tables))
+	bust := func(p int) {
+		tn.Players[p].Stack = 0
+		a.NoError(tn.Decline(p))
+	}
+
+	// eight players fit around two tables so the short table is broken
+	bust(0)
+	a.True(tn.Tables[0].Broken)
+	a.Equal(4, len(seated(tn.Tables[1])))
+	a.Equal(4, len(seated(tn.Tables[2])))
+	var moved []int
+	for _, e := range tn.Events {
+		if e.Type == Moved {
+			a.Equal(0, e.FromTable)
+			moved = append(moved, e.Player)
+		}
+	}
+	a.ElementsMatch([]int{3, 6}, moved)
+	_, err = tn.Deal(0, mixed())
+	a.Error(err)
+
+	// the player due the big blind moves to the short table
+	bb := tn.Tables[2].Seats[tn.bigBlind(tn.Tables[2])]
+	for _, p := range seated(tn.Tables[1])[:2] {
+		bust(p)
+	}
+	a.Equal(3, len(seated(tn.Tables[1])))
+	a.Equal(3, len(seated(tn.Tables[2])))
+	a.Equal(1, tn.Players[bb].Table)
+	last := tn.Events[len(tn.Events)-1]
+	a.Equal(Moved, last.Type)
+	a.Equal(bb, last.Player)
+	a.Equal(fmt.Sprintf("hand 0: %s moved from table 3 seat %d to table 2 seat %d",
+		names[bb], last.FromSeat+1, last.Seat+1), last.String())
+
+	// down to a table's worth the rest are redrawn to the final table
+	bust(seated(tn.Tables[2])[0])
+	bust(seated(tn.Tables[2])[0])
+	a.True(tn.Tables[2].Broken)
+	a.Equal(4, len(seated(tn.Tables[1])))
+	a.Equal(4, tn.Remaining())
+	for _, p := range tn.Players {
+		if p.Place == 0 {
+			a.Equal(1, p.Table)
+		}
+	}
+	e := tn.Events[len(tn.Events)-5]
+	a.Equal(FinalTable, e.Type)
+	a.Equal("hand 0: final table at table 2", e.String())
+}
+
+func TestBalanceBusyTable(t *testing.T) {
+	a := assert.New(t)
+	cfg := config()
+	cfg.TableSize = 6
+	var names []string
+	for i := 0; i < 24; i++ {
+		names = append(names, fmt.Sprint("player", i))
+	}
+	tn, err := New(cfg, names)
+	a.NoError(err)
+	a.Equal(4, len(tn.Tables))
+	_, err = tn.Deal(0, mixed())
+	a.NoError(err)
+
+	// the biggest table is playing a hand, so the next biggest
+	// makes up the short table instead
+	for _, p := range seated(tn.Tables[1])[:2] {
+		tn.Players[p].Stack = 0
+		a.NoError(tn.Decline(p))
+	}
+	a.Equal(6, len(seated(tn.Tables[0])))
+	a.Equal(5, len(seated(tn.Tables[1])))
+	a.Equal(5, len(seated(tn.Tables[2])))
+	a.Equal(6, len(seated(tn.Tables[3])))
+	last := tn.Events[len(tn.Events)-1]
+	a.Equal(Moved, last.Type)
+	a.Equal(2, last.FromTable)
+	a.Equal(1, last.Table)
+}
+
+func TestRebuy(t *testing.T) {
+	a := assert.New(t)
+	cfg := config()
+	cfg.Rebuy = Rebuy{Cost: 100, Chips: 100, Max: 1, Levels: 1}
+	cfg.AddOn = AddOn{Cost: 50, Chips: 200, Level: 2}
+	tn, err := New(cfg, []string{"ann", "bob", "cat"})
+	a.NoError(err)
+	a.Error(tn.Rebuy(0))
+
+	g, err := tn.Play(0, deck.New(), shove)
+	a.NoError(err)
+	var busted []int
+	for i, s := range g.Seats {
+		if s.Stack == 0 {
+			busted = append(busted, i)
+		}
+	}
+	a.Equal(2, len(busted))
+	// busted players wait on a rebuy
+	a.Equal(3, tn.Remaining())
+	a.NoError(tn.Rebuy(busted[0]))
+	a.Equal(100, tn.Players[busted[0]].Stack)
+	a.Error(tn.Rebuy(busted[0]))
+	a.Equal(400, tn.PrizePool())
+
+	a.Error(tn.Decline(busted[0]))
+	a.Error(tn.AddOn(busted[0]))
+	// the rebuy period ends
+	tn.Advance(40 * time.Minute)
+	a.Equal(2, tn.Level)
+	a.Equal(3, tn.Players[busted[1]].Place)
+	a.Equal(-1, tn.Tables[0].Seats[busted[1]])
+	a.Error(tn.Rebuy(busted[1]))
+	a.NoError(tn.AddOn(busted[0]))
+	a.Error(tn.AddOn(busted[0]))
+	a.Equal(300, tn.Players[busted[0]].Stack)
+	a.Equal(450, tn.PrizePool())
+}
+
+func TestDecline(t *testing.T) {
+	a := assert.New(t)
+	cfg := config()
+	cfg.Rebuy = Rebuy{Cost: 100, Chips: 100, Max: 1, Levels: 1}
+	tn, err := New(cfg, []string{"ann", "bob"})
+	a.NoError(err)
+	g, err := tn.Play(0, mixed(), shove)
+	a.NoError(err)
+	loser := 0
+	if g.Seats[1].Stack == 0 {
+		loser = 1
+	}
+	a.False(tn.Done())
+	_, err = tn.Deal(0, deck.New())
+	a.Error(err)
+	a.NoError(tn.Decline(loser))
+	a.True(tn.Done())
+	a.Equal(2, tn.Players[loser].Place)
+	a.Equal(1, tn.Players[1-loser].Place)
+	results := tn.Results()
+	a.Equal(100, results[0].Prize)
+	a.Equal(60, results[1].Prize)
+}
+
+func TestSimultaneousBust(t *testing.T) {
+	a := assert.New(t)
+	tn, err := New(config(), []string{"ann", "bob", "cat"})
+	a.NoError(err)
+	g, err := tn.Deal(0, deck.New())
+	a.NoError(err)
+	for !g.Done() {
+		a.NoError(g.Act(shove(g)))
+	}
+	var winner int
+	for i, w := range g.Winnings {
+		if w > 0 {
+			winner = i
+		}
+	}
+	// the player who started the hand with fewer chips finishes lower
+	tn.Players[(winner+1)%3].Stack = 50
+	a.NoError(tn.Finish(0, g))
+	a.True(tn.Done())
+	a.Equal(1, tn.Players[winner].Place)
+	a.Equal(2, tn.Players[(winner+2)%3].Place)
+	a.Equal(3, tn.Players[(winner+1)%3].Place)
+}
@@ -0,0 +1,234 @@
+package videopoker
+
+import (
+	"fmt"
+
+	"github.com/aultimus/gosouth/card"
+	"github.com/aultimus/gosouth/hand"
+)
+
+// HAND represents the paying hands of a video poker game
+type HAND int
+
+const (
+	// Nothing constant
+	Nothing = HAND(iota)
+	// JacksOrBetter constant, a pair of jacks or better
+	JacksOrBetter = HAND(iota)
+	// TwoPair constant
+	TwoPair = HAND(iota)
+	// ThreeOfAKind constant
+	ThreeOfAKind = HAND(iota)
+	// Straight constant
+	Straight = HAND(iota)
+	// Flush constant
+	Flush = HAND(iota)
+	// FullHouse constant
+	FullHouse = HAND(iota)
+	// FourOfAKind constant, for Bonus Poker fives through kings
+	FourOfAKind = HAND(iota)
+	// FourTwosToFours constant, Bonus Poker four twos, threes or fours
+	FourTwosToFours = HAND(iota)
+	// FourAces constant, Bonus Poker four aces
+	FourAces = HAND(iota)
+	// StraightFlush constant
+	StraightFlush = HAND(iota)
+	// FiveOfAKind constant, Deuces Wild only
+	FiveOfAKind = HAND(iota)
+	// WildRoyalFlush constant, Deuces Wild royal flush made with a deuce
+	WildRoyalFlush = HAND(iota)
+	// FourDeuces constant, Deuces Wild only
+	FourDeuces = HAND(iota)
+	// RoyalFlush constant, a natural royal flush
+	RoyalFlush = HAND(iota)
+)
+
+var handNames = map[HAND]string{
+	Nothing:         "Nothing",
+	JacksOrBetter:   "Jacks or Better",
+	TwoPair:         "Two Pair",
+	ThreeOfAKind:    "Three of a Kind",
+	Straight:        "Straight",
+	Flush:           "Flush",
+	FullHouse:       "Full House",
+	FourOfAKind:     "Four of a Kind",
+	FourTwosToFours: "Four 2s-4s",
+	FourAces:        "Four Aces",
+	StraightFlush:   "Straight Flush",
+	FiveOfAKind:     "Five of a Kind",
+	WildRoyalFlush:  "Wild Royal Flush",
+	FourDeuces:      "Four Deuces",
+	RoyalFlush:      "Royal Flush",
+}
+
+func (h HAND) String() string {
+	return handNames[h]
+}
+
+// GAME represents the rules used to classify a hand
+type GAME int
+
+const (
+	// JacksOrBetterGame pays a pair of jacks or better upwards
+	JacksOrBetterGame = GAME(iota)
+	// BonusPokerGame is Jacks or Better with bonus pays for some quads
+	BonusPokerGame = GAME(iota)
+	// DeucesWildGame has every two wild and pays three of a kind upwards
+	DeucesWildGame = GAME(iota)
+)
+
+// Paytable is a video poker game and its pays per coin bet.
+// Royal flushes are paid at the maximum coin rate.
+type Paytable struct {
+	Name string
+	Game GAME
+	Pays map[HAND]int
+}
+
+// JacksOrBetter96 is full pay 9/6 Jacks or Better, returning 99.54%
+var JacksOrBetter96 = &Paytable{
+	Name: "Jacks or Better 9/6",
+	Game: JacksOrBetterGame,
+	Pays: map[HAND]int{
+		RoyalFlush:    800,
+		StraightFlush: 50,
+		FourOfAKind:   25,
+		FullHouse:     9,
+		Flush:         6,
+		Straight:      4,
+		ThreeOfAKind:  3,
+		TwoPair:       2,
+		JacksOrBetter: 1,
+	},
+}
+
+// DeucesWild is full pay Deuces Wild, returning 100.76%
+var DeucesWild = &Paytable{
+	Name: "Deuces Wild",
+	Game: DeucesWildGame,
+	Pays: map[HAND]int{
+		RoyalFlush:     800,
+		FourDeuces:     200,
+		WildRoyalFlush: 25,
+		FiveOfAKind:    15,
+		StraightFlush:  9,
+		FourOfAKind:    5,
+		FullHouse:      3,
+		Flush:          2,
+		Straight:       2,
+		ThreeOfAKind:   1,
+	},
+}
+
+// BonusPoker is 8/5 Bonus Poker, returning 99.17%
+var BonusPoker = &Paytable{
+	Name: "Bonus Poker 8/5",
+	Game: BonusPokerGame,
+	Pays: map[HAND]int{
+		RoyalFlush:      800,
+		StraightFlush:   50,
+		FourAces:        80,
+		FourTwosToFours: 40,
+		FourOfAKind:     25,
+		FullHouse:       8,
+		Flush:           5,
+		Straight:        4,
+		ThreeOfAKind:    3,
+		TwoPair:         2,
+		JacksOrBetter:   1,
+	},
+}
+
+// Classify returns the paying hand that a five card hand makes,
+// using the hand evaluator
+func (p *Paytable) Classify(h hand.Hand) (HAND, error) {
+	if len(h) != sizeHand {
+		return Nothing, fmt.Errorf("video poker hand should be %d cards, not %d cards",
+			sizeHand, len(h))
+	}
+	if p.Game == DeucesWildGame {
+		return classifyDeucesWild(h)
+	}
+	v, err := hand.FormHand(h)
+	if err != nil {
+		return Nothing, err
+	}
+	switch v.Rank {
+	case hand.RoyalFlush:
+		return RoyalFlush, nil
+	case hand.StraightFlush:
+		return StraightFlush, nil
+	case hand.FourOfAKind:
+		if p.Game == BonusPokerGame {
+			switch v.Hand[0].Rank {
+			case card.Ace:
+				return FourAces, nil
+			case card.Two, card.Three, card.Four:
+				return FourTwosToFours, nil
+			}
+		}
+		return FourOfAKind, nil
+	case hand.FullHouse:
+		return FullHouse, nil
+	case hand.Flush:
+		return Flush, nil
+	case hand.Straight:
+		return Straight, nil
+	case hand.ThreeOfAKind:
+		return ThreeOfAKind, nil
+	case hand.TwoPair:
+		return TwoPair, nil
+	case hand.OnePair:
+		if card.RankIndexes[v.Hand[0].Rank] >= card.RankIndexes[card.Jack] {
+			return JacksOrBetter, nil
+		}
+	}
+	return Nothing, nil
+}
+
+func classifyDeucesWild(h hand.Hand) (HAND, error) {
+	numDeuces := 0
+	for _, c := range h {
+		if hand.DeucesWild.IsWild(c) {
+			numDeuces++
+		}
+	}
+	if numDeuces == 4 {
+		return FourDeuces, nil
+	}
+	v, err := hand.FormHandWild(h, hand.DeucesWild)
+	if err != nil {
+		return Nothing, err
+	}
+	switch v.Rank {
+	case hand.RoyalFlush:
+		if numDeuces == 0 {
+			return RoyalFlush, nil
+		}
+		return WildRoyalFlush, nil
+	case hand.FiveOfAKind:
+		return FiveOfAKind, nil
+	case hand.StraightFlush:
+		return StraightFlush, nil
+	case hand.FourOfAKind:
+		return FourOfAKind, nil
+	case hand.FullHouse:
+		return FullHouse, nil
+	case hand.Flush:
+		return Flush, nil
+	case hand.Straight:
+		return Straight, nil
+	case hand.ThreeOfAKind:
+		return ThreeOfAKind, nil
+	}
+	return Nothing, nil
+}
+
+// Pay returns the number of coins paid per coin bet for a five card hand
+func (p *Paytable) Pay(h hand.Hand) (int, error) {
+	c, err := p.Classify(h)
+	if err != nil {
+		return 0, err
+	}
+	return p.Pays[c], nil
+}
@@ -0,0 +1,354 @@
+package videopoker
+
+import (
+	"fmt"
+	"math/bits"
+	"sort"
+
+	"github.com/aultimus/gosouth/card"
+	"github.com/aultimus/gosouth/hand"
+)
+
+const (
+	sizeHand = 5
+	numHolds = 1 << sizeHand
+)
+
+// Cards are handled internally as deck indexes, suit*NumRanks+rank,
+// which matches the order of deck.New
+
+func index(c *card.Card) int {
+	return card.SuitIndexes[c.Suit]*card.NumRanks + card.RankIndexes[c.Rank]
+}
+
+var (
+	// royalMask is the rank mask of T J Q K A
+	royalMask = uint16(0x1f) << 8
+	// straightMasks are the rank masks of every straight, the wheel first
+	straightMasks = func() []uint16 {
+		m := []uint16{1<<12 | 0xf}
+		for low := 0; low+sizeHand <= card.NumRanks; low++ {
+			m = append(m, uint16(0x1f)<<uint(low))
+		}
+		return m
+	}()
+	binomials = func() [card.NumCards + 1][sizeHand + 1]int {
+		var b [card.NumCards + 1][sizeHand + 1]int
+		for n := 0; n <= card.NumCards; n++ {
+			b[n][0] = 1
+			for k := 1; k <= sizeHand && k <= n; k++ {
+				b[n][k] = b[n-1][k-1] + b[n-1][k]
+			}
+		}
+		return b
+	}()
+)
+
+// fitsStraight returns true if the ranks in mask
+// are all part of a single straight
+func fitsStraight(mask uint16) bool {
+	for _, s := range straightMasks {
+		if mask&s == mask {
+			return true
+		}
+	}
+	return false
+}
+
+// classify returns the paying hand of five cards given as deck indexes.
+// It is the fast equivalent of Classify used when enumerating draws.
+func (p *Paytable) classify(c []int) HAND {
+	var counts [card.NumRanks]int
+	var mask uint16
+	numWild, suit, isFlush := 0, -1, true
+	for _, x := range c {
+		r, s := x%card.NumRanks, x/card.NumRanks
+		if p.Game == DeucesWildGame && r == 0 {
+			numWild++
+			continue
+		}
+		counts[r]++
+		mask |= 1 << uint(r)
+		if suit == -1 {
+			suit = s
+		} else if s != suit {
+			isFlush = false
+		}
+	}
+	maxCount, pairs, quadRank := 0, 0, 0
+	for r, n := range counts {
+		if n > maxCount {
+			maxCount = n
+		}
+		if n == 2 {
+			pairs++
+		}
+		if n == 4 {
+			quadRank = r
+		}
+	}
+	distinct := bits.OnesCount16(mask) == sizeHand-numWild
+	isStraight := distinct && fitsStraight(mask)
+
+	if p.Game == DeucesWildGame {
+		switch {
+		case numWild == 4:
+			return FourDeuces
+		case numWild == 0 && isFlush && mask == royalMask:
+			return RoyalFlush
+		case isFlush && distinct && mask&royalMask == mask:
+			return WildRoyalFlush
+		case maxCount+numWild == 5:
+			return FiveOfAKind
+		case isFlush && isStraight:
+			return StraightFlush
+		case maxCount+numWild == 4:
+			return FourOfAKind
+		case numWild == 0 && maxCount == 3 && pairs == 1, numWild == 1 && pairs == 2:
+			return FullHouse
+		case isFlush:
+			return Flush
+		case isStraight:
+			return Straight
+		case maxCount+numWild == 3:
+			return ThreeOfAKind
+		}
+		return Nothing
+	}
+
+	switch {
+	case isFlush && mask == royalMask:
+		return RoyalFlush
+	case isFlush && isStraight:
+		return StraightFlush
+	case maxCount == 4:
+		if p.Game == BonusPokerGame {
+			if quadRank == card.RankIndexes[card.Ace] {
+				return FourAces
+			} else if quadRank <= card.RankIndexes[card.Four] {
+				return FourTwosToFours
+			}
+		}
+		return FourOfAKind
+	case maxCount == 3 && pairs == 1:
+		return FullHouse
+	case isFlush:
+		return Flush
+	case isStraight:
+		return Straight
+	case maxCount == 3:
+		return ThreeOfAKind
+	case pairs == 2:
+		return TwoPair
+	case pairs == 1:
+		for r := card.RankIndexes[card.Jack]; r < card.NumRanks; r++ {
+			if counts[r] == 2 {
+				return JacksOrBetter
+			}
+		}
+	}
+	return Nothing
+}
+
+// Hold is one of the 32 ways to play a dealt hand
+type Hold struct {
+	// Mask has bit i set if the card at index i of the dealt hand is held
+	Mask int
+	Held hand.Hand
+	// EV is the expected return per coin bet
+	EV float64
+	// Counts is the number of draws that make each paying hand
+	Counts map[HAND]int
+	// Draws is the number of possible draws
+	Draws int
+}
+
+// Holds calculates the expected return of all 32 hold options for a
+// dealt five card hand by enumerating every possible draw.
+// They are returned best first.
+func (p *Paytable) Holds(h hand.Hand) ([]*Hold, error) {
+	dealt, err := indexes(h)
+	if err != nil {
+		return nil, err
+	}
+	inHand := make(map[int]bool)
+	for _, x := range dealt {
+		inHand[x] = true
+	}
+	var stub []int
+	for x := 0; x < card.NumCards; x++ {
+		if !inHand[x] {
+			stub = append(stub, x)
+		}
+	}
+
+	var holds []*Hold
+	for mask := 0; mask < numHolds; mask++ {
+		hold := &Hold{Mask: mask, Counts: make(map[HAND]int)}
+		final := make([]int, 0, sizeHand)
+		for i, x := range dealt {
+			if mask&(1<<uint(i)) != 0 {
+				hold.Held = append(hold.Held, h[i])
+				final = append(final, x)
+			}
+		}
+		numHeld := len(final)
+		var counts [RoyalFlush + 1]int
+		forEachComb(len(stub), sizeHand-numHeld, func(idx []int) {
+			final = final[:numHeld]
+			for _, i := range idx {
+				final = append(final, stub[i])
+			}
+			counts[p.classify(final)]++
+		})
+		total := 0
+		for c, n := range counts {
+			if n > 0 {
+				hold.Counts[HAND(c)] = n
+				hold.Draws += n
+				total += n * p.Pays[HAND(c)]
+			}
+		}
+		hold.EV = float64(total) / float64(hold.Draws)
+		holds = append(holds, hold)
+	}
+	sort.SliceStable(holds, func(i, j int) bool {
+		return holds[i].EV > holds[j].EV
+	})
+	return holds, nil
+}
+
+// BestHold returns the hold option with the highest expected return
+func (p *Paytable) BestHold(h hand.Hand) (*Hold, error) {
+	holds, err := p.Holds(h)
+	if err != nil {
+		return nil, err
+	}
+	return holds[0], nil
+}
+
+// Return calculates the expected return per coin of the game played with
+// optimal strategy, e.g. 0.9954 for 9/6 Jacks or Better. It is exhaustive
+// over all 2,598,960 deals so takes several seconds.
+//
+// For a deal D and held cards H the total paid over every draw is the
+// total paid over all hands containing H, less those also containing a
+// discarded card, which by inclusion-exclusion is the alternating sum over
+// every S with H ⊆ S ⊆ D of the total paid by all hands containing S.
+// These totals are tabulated once for every subset of up to five cards.
+func (p *Paytable) Return() float64 {
+	// totals[k][rank] is the total paid by all hands
+	// containing the k card subset with colex rank rank
+	var totals [sizeHand + 1][]int64
+	for k := range totals {
+		totals[k] = make([]int64, binomials[card.NumCards][k])
+	}
+	sub := make([]int, 0, sizeHand)
+	forEachComb(card.NumCards, sizeHand, func(d []int) {
+		pay := int64(p.Pays[p.classify(d)])
+		for mask := 0; mask < numHolds; mask++ {
+			sub = subset(d, mask, sub)
+			totals[len(sub)][colex(sub)] += pay
+		}
+	})
+
+	var total float64
+	var supersets [numHolds]int64
+	forEachComb(card.NumCards, sizeHand, func(d []int) {
+		for mask := 0; mask < numHolds; mask++ {
+			sub = subset(d, mask, sub)
+			supersets[mask] = totals[len(sub)][colex(sub)]
+		}
+		best := 0.0
+		for hold := 0; hold < numHolds; hold++ {
+			var sum int64
+			discards := (numHolds - 1) ^ hold
+			for a := discards; ; a = (a - 1) & discards {
+				if bits.OnesCount(uint(a))%2 == 0 {
+					sum += supersets[hold|a]
+				} else {
+					sum -= supersets[hold|a]
+				}
+				if a == 0 {
+					break
+				}
+			}
+			numDraw := sizeHand - bits.OnesCount(uint(hold))
+			ev := float64(sum) / float64(binomials[card.NumCards-sizeHand][numDraw])
+			if ev > best {
+				best = ev
+			}
+		}
+		total += best
+	})
+	return total / float64(binomials[card.NumCards][sizeHand])
+}
+
+func indexes(h hand.Hand) ([]int, error) {
+	if len(h) != sizeHand {
+		return nil, fmt.Errorf("video poker hand should be %d cards, not %d cards",
+			sizeHand, len(h))
+	}
+	seen := make(map[int]bool)
+	var d []int
+	for _, c := range h {
+		if _, ok := card.RankIndexes[c.Rank]; !ok {
+			return nil, fmt.Errorf("%s is not a valid card", c)
+		}
+		if _, ok := card.SuitIndexes[c.Suit]; !ok {
+			return nil, fmt.Errorf("%s is not a valid card", c)
+		}
+		x := index(c)
+		if seen[x] {
+			return nil, fmt.Errorf("%s appears more than once", c)
+		}
+		seen[x] = true
+		d = append(d, x)
+	}
+	return d, nil
+}
+
+// subset returns the elements of d selected by mask, reusing buf
+func subset(d []int, mask int, buf []int) []int {
+	buf = buf[:0]
+	for i, x := range d {
+		if mask&(1<<uint(i)) != 0 {
+			buf = append(buf, x)
+		}
+	}
+	return buf
+}
+
+// colex returns the colexicographic rank of an ascending combination
+func colex(c []int) int {
+	r := 0
+	for i, x := range c {
+		r += binomials[x][i+1]
+	}
+	return r
+}
+
+// forEachComb calls f with every ascending combination of k indexes
+// from 0 to n-1, the slice passed to f is reused between calls
+func forEachComb(n, k int, f func([]int)) {
+	idx := make([]int, k)
+	for i := range idx {
+		idx[i] = i
+	}
+	if k > n {
+		return
+	}
+	for {
+		f(idx)
+		i := k - 1
+		for ; i >= 0 && idx[i] == i+n-k; i-- {
+		}
+		if i < 0 {
+			return
+		}
+		idx[i]++
+		for j := i + 1; j < k; j++ {
+			idx[j] = idx[j-1] + 1
+		}
+	}
+}
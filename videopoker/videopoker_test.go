@@ -0,0 +1,136 @@
+package videopoker
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/aultimus/gosouth/card"
+	"github.com/aultimus/gosouth/deck"
+	"github.com/aultimus/gosouth/hand"
+	"github.com/stretchr/testify/assert"
+)
+
+var paytables = []*Paytable{JacksOrBetter96, BonusPoker, DeucesWild}
+
+func mkHand(cards ...string) hand.Hand {
+	var h hand.Hand
+	for _, s := range cards {
+		h = append(h, card.New(card.RANK(s[:1]), card.SUIT(s[1:])))
+	}
+	return h
+}
+
+func TestClassify(t *testing.T) {
+	a := assert.New(t)
+	cases := []struct {
+		p *Paytable
+		h hand.Hand
+		e HAND
+	}{
+		{JacksOrBetter96, mkHand("TS", "JS", "QS", "KS", "AS"), RoyalFlush},
+		{JacksOrBetter96, mkHand("JS", "JD", "4S", "5H", "9C"), JacksOrBetter},
+		{JacksOrBetter96, mkHand("TS", "TD", "4S", "5H", "9C"), Nothing},
+		{JacksOrBetter96, mkHand("AS", "AD", "AH", "AC", "9C"), FourOfAKind},
+		{BonusPoker, mkHand("AS", "AD", "AH", "AC", "9C"), FourAces},
+		{BonusPoker, mkHand("3S", "3D", "3H", "3C", "9C"), FourTwosToFours},
+		{BonusPoker, mkHand("5S", "5D", "5H", "5C", "9C"), FourOfAKind},
+		{DeucesWild, mkHand("2S", "2D", "2H", "2C", "9C"), FourDeuces},
+		{DeucesWild, mkHand("TS", "JS", "QS", "KS", "AS"), RoyalFlush},
+		{DeucesWild, mkHand("TS", "JS", "2H", "KS", "AS"), WildRoyalFlush},
+		{DeucesWild, mkHand("9S", "9D", "2H", "2C", "9C"), FiveOfAKind},
+		{DeucesWild, mkHand("AS", "3S", "2H", "5S", "4S"), StraightFlush},
+		{DeucesWild, mkHand("AS", "AD", "2H", "KS", "KD"), FullHouse},
+		{DeucesWild, mkHand("JS", "JD", "4S", "5H", "9C"), Nothing},
+		{DeucesWild, mkHand("JS", "JD", "2S", "5H", "9C"), ThreeOfAKind},
+	}
+	for _, c := range cases {
+		h, err := c.p.Classify(c.h)
+		a.NoError(err)
+		a.Equal(c.e, h, "%s %s", c.p.Name, c.h)
+		d, err := indexes(c.h)
+		a.NoError(err)
+		a.Equal(c.e, c.p.classify(d), "%s %s", c.p.Name, c.h)
+	}
+
+	_, err := JacksOrBetter96.Classify(mkHand("JS", "JD", "4S", "5H"))
+	a.Error(err)
+}
+
+// The fast classifier used for enumeration must agree with
+// the hand evaluator
+func TestClassifyMatchesEvaluator(t *testing.T) {
+	a := assert.New(t)
+	rng := rand.New(rand.NewSource(1))
+	d := deck.New()
+	for i := 0; i < 20000; i++ {
+		rng.Shuffle(len(d), func(i, j int) { d[i], d[j] = d[j], d[i] })
+		h := hand.Hand(d[:sizeHand])
+		idx, err := indexes(h)
+		a.NoError(err)
+		for _, p := range paytables {
+			e, err := p.Classify(h)
+			a.NoError(err)
+			if !a.Equal(e, p.classify(idx), "%s %s", p.Name, h) {
+				return
+			}
+		}
+	}
+}
+
+func TestHolds(t *testing.T) {
+	a := assert.New(t)
+
+	// a dealt royal is held
+	best, err := JacksOrBetter96.BestHold(mkHand("TS", "JS", "QS", "KS", "AS"))
+	a.NoError(err)
+	a.Equal(numHolds-1, best.Mask)
+	a.Equal(800.0, best.EV)
+	a.Equal(1, best.Draws)
+
+	// four to a royal beats a made flush
+	holds, err := JacksOrBetter96.Holds(mkHand("TS", "JS", "QS", "KS", "3S"))
+	a.NoError(err)
+	a.Equal(numHolds, len(holds))
+	a.Equal(0xf, holds[0].Mask)
+	a.Equal(47, holds[0].Draws)
+	// 1 royal, 1 straight flush, 6 other flushes, 6 straights, 9 high pairs
+	a.Equal(1, holds[0].Counts[RoyalFlush])
+	a.Equal(1, holds[0].Counts[StraightFlush])
+	a.Equal(6, holds[0].Counts[Flush])
+	a.Equal(6, holds[0].Counts[Straight])
+	a.Equal(9, holds[0].Counts[JacksOrBetter])
+	a.InDelta((800.0+50+6*6+6*4+9)/47, holds[0].EV, 1e-9)
+
+	// drawing five new cards
+	for _, h := range holds {
+		if h.Mask == 0 {
+			a.Equal(1533939, h.Draws)
+		}
+	}
+
+	_, err = JacksOrBetter96.Holds(mkHand("TS", "TS", "QS", "KS", "3S"))
+	a.Error(err)
+}
+
+func TestColex(t *testing.T) {
+	a := assert.New(t)
+	seen := make(map[int]bool)
+	forEachComb(10, 3, func(c []int) {
+		r := colex(c)
+		a.False(seen[r])
+		a.True(r >= 0 && r < binomials[10][3])
+		seen[r] = true
+	})
+	a.Equal(binomials[10][3], len(seen))
+}
+
+// Long running test
+func TestReturn(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping TestReturn as in short mode")
+	}
+	a := assert.New(t)
+	a.InDelta(0.995439, JacksOrBetter96.Return(), 1e-6)
+	a.InDelta(0.991660, BonusPoker.Return(), 1e-6)
+	a.InDelta(1.007619, DeucesWild.Return(), 1e-6)
+}